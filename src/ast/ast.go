@@ -3,6 +3,7 @@ package ast
 import (
 	"Goslang/token"
 	"bytes"
+	"fmt"
 	"strings"
 )
 
@@ -11,6 +12,7 @@ import (
 type Node interface {
 	TokenLiteral() string
 	String() string
+	Pos() token.Position //position where the node starts, e.g. the left operand of an infix expression
 }
 
 type Statement interface {
@@ -27,6 +29,13 @@ type Program struct {
 	Statements []Statement
 }
 
+func (p *Program) Pos() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return token.Position{}
+}
+
 func (p *Program) TokenLiteral() string {
 	if len(p.Statements) > 0 {
 		return p.Statements[0].TokenLiteral()
@@ -37,15 +46,21 @@ func (p *Program) TokenLiteral() string {
 
 // VAR STATEMENT :*
 type VarStatement struct {
-	Token token.Token //the VAR token
-	Name  *Identifier
-	Value Expression
+	Token  token.Token //the VAR token
+	Access string      //the access modifier, e.g.: "private", empty when none was written
+	Doc    string      //the comment right above the declaration
+	Name   *Identifier
+	Value  Expression
 }
 
 func (vs *VarStatement) statementNode()       {}
 func (vs *VarStatement) TokenLiteral() string { return vs.Token.Literal }
+func (vs *VarStatement) Pos() token.Position  { return vs.Token.Pos }
 func (vs *VarStatement) String() string {
 	var out bytes.Buffer
+	if vs.Access != "" {
+		out.WriteString(vs.Access + " ")
+	}
 	out.WriteString(vs.TokenLiteral() + " ")
 	out.WriteString(vs.Name.String())
 	out.WriteString(" = ")
@@ -67,6 +82,7 @@ type Identifier struct {
 
 func (i *Identifier) expressionNode()      {}
 func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
+func (i *Identifier) Pos() token.Position  { return i.Token.Pos }
 func (i *Identifier) String() string       { return i.Value }
 
 //RETURN STATEMENT ;)
@@ -78,6 +94,7 @@ type ReturnStatement struct {
 
 func (rs *ReturnStatement) statementNode()       {}
 func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Literal }
+func (rs *ReturnStatement) Pos() token.Position  { return rs.Token.Pos }
 func (rs *ReturnStatement) String() string {
 	var out bytes.Buffer
 
@@ -104,6 +121,7 @@ type BlockStatement struct {
 
 func (bs *BlockStatement) statementNode()       {}
 func (bs *BlockStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BlockStatement) Pos() token.Position  { return bs.Token.Pos }
 func (bs *BlockStatement) String() string {
 	var out bytes.Buffer
 
@@ -118,16 +136,69 @@ type TypeAnnotation struct {
 	Value string
 }
 
+func (ta *TypeAnnotation) Pos() token.Position { return ta.Token.Pos }
+
 func (t *TypeAnnotation) String() string {
 	return t.Value
 }
 
 type FunctionParameter struct {
-	Name *Identifier
-	Type *TypeAnnotation // this is the type annotation for the parameter
+	Name     *Identifier
+	Type     *TypeAnnotation // this is the type annotation for the parameter
+	Default  Expression      //the value of the parameter when its argument is left out, e.g.: y:int = 10
+	Variadic bool            //the last parameter collects the extra arguments in an array, e.g.: ...rest:int
+}
+
+func (fp *FunctionParameter) String() string {
+	param := fp.Name.String()
+	if fp.Variadic {
+		param = "..." + param
+	}
+	if fp.Type != nil {
+		param += ":" + fp.Type.String()
+	}
+	if fp.Default != nil {
+		param += " = " + fp.Default.String()
+	}
+	return param
 }
+
+// Accepts reports whether a function with params can be called with n arguments.
+func Accepts(params []*FunctionParameter, n int) bool {
+	required, variadic := arity(params)
+	return n >= required && (variadic || n <= len(params))
+}
+
+// Arity describes the number of arguments a function with params accepts, e.g.: "2", "1 to 2", "at least 1".
+func Arity(params []*FunctionParameter) string {
+	required, variadic := arity(params)
+	switch {
+	case variadic:
+		return fmt.Sprintf("at least %d", required)
+	case required < len(params):
+		return fmt.Sprintf("%d to %d", required, len(params))
+	default:
+		return fmt.Sprintf("%d", required)
+	}
+}
+
+func arity(params []*FunctionParameter) (required int, variadic bool) {
+	for _, param := range params {
+		if param.Variadic {
+			return required, true
+		}
+		if param.Default == nil {
+			required++
+		}
+	}
+	return required, false
+}
+
 type FunctionStatement struct {
 	Token      token.Token
+	Access     string      //the access modifier, e.g.: "private", empty when none was written
+	Doc        string      //the comment right above the declaration
+	Receiver   *Identifier //the struct name of a method, e.g.: Point in fn Point.area()
 	Name       *Identifier
 	Parameters []*FunctionParameter
 	ReturnType *TypeAnnotation
@@ -137,16 +208,22 @@ type FunctionStatement struct {
 func (fn *FunctionStatement) statementNode()       {}
 func (fn *FunctionStatement) expressionNode()      {}
 func (fn *FunctionStatement) TokenLiteral() string { return fn.Token.Literal }
+func (fn *FunctionStatement) Pos() token.Position  { return fn.Token.Pos }
 func (fn *FunctionStatement) String() string {
 	var out bytes.Buffer
 
 	parameters := []string{}
 	for _, param := range fn.Parameters {
-		paramStr := param.Name.String() + ":" + param.Type.String()
-		parameters = append(parameters, paramStr)
+		parameters = append(parameters, param.String())
 	}
 
+	if fn.Access != "" {
+		out.WriteString(fn.Access + " ")
+	}
 	out.WriteString(fn.TokenLiteral() + " ")
+	if fn.Receiver != nil {
+		out.WriteString(fn.Receiver.String() + ".")
+	}
 	out.WriteString(fn.Name.String())
 	out.WriteString("(")
 	out.WriteString(strings.Join(parameters, ", "))
@@ -176,13 +253,13 @@ type FunctionLiteral struct {
 
 func (fl *FunctionLiteral) expressionNode()      {}
 func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FunctionLiteral) Pos() token.Position  { return fl.Token.Pos }
 func (fl *FunctionLiteral) String() string {
 	var out bytes.Buffer
 
 	params := []string{}
 	for _, param := range fl.Parameters {
-		paramStr := param.Name.String() + ":" + param.Type.String()
-		params = append(params, paramStr)
+		params = append(params, param.String())
 	}
 
 	out.WriteString(fl.TokenLiteral() + " ")
@@ -210,6 +287,7 @@ type ExpressionStatement struct {
 
 func (es *ExpressionStatement) statementNode()       {}
 func (es *ExpressionStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ExpressionStatement) Pos() token.Position  { return es.Token.Pos }
 
 func (p *Program) String() string {
 	var out bytes.Buffer
@@ -235,8 +313,19 @@ type IntegerLiteral struct {
 
 func (il *IntegerLiteral) expressionNode()      {}
 func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
+func (il *IntegerLiteral) Pos() token.Position  { return il.Token.Pos }
 func (il *IntegerLiteral) String() string       { return il.Token.Literal }
 
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode()      {}
+func (fl *FloatLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FloatLiteral) Pos() token.Position  { return fl.Token.Pos }
+func (fl *FloatLiteral) String() string       { return fl.Token.Literal }
+
 type PrefixExpression struct {
 	Token    token.Token //the prefix token, "!", "-"
 	Operator string
@@ -245,6 +334,7 @@ type PrefixExpression struct {
 
 func (pe *PrefixExpression) expressionNode()      {}
 func (pe *PrefixExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PrefixExpression) Pos() token.Position  { return pe.Token.Pos }
 func (pe *PrefixExpression) String() string {
 	var out bytes.Buffer
 
@@ -265,6 +355,7 @@ type InfixExpression struct {
 
 func (ie *InfixExpression) expressionNode()      {}
 func (ie *InfixExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *InfixExpression) Pos() token.Position  { return ie.Left.Pos() }
 func (ie *InfixExpression) String() string {
 	var out bytes.Buffer
 
@@ -282,7 +373,8 @@ type Boolean struct {
 	Value bool
 }
 
-func (b *Boolean) expressionNode() {}
+func (b *Boolean) expressionNode()     {}
+func (b *Boolean) Pos() token.Position { return b.Token.Pos }
 func (b *Boolean) TokenLiteral() string {
 	if b.Token.Type == "TRUTH" {
 		return "truth"
@@ -303,6 +395,7 @@ type IfExpression struct {
 
 func (ie *IfExpression) expressionNode()      {}
 func (ie *IfExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IfExpression) Pos() token.Position  { return ie.Token.Pos }
 func (ie *IfExpression) String() string {
 	var out bytes.Buffer
 
@@ -320,13 +413,25 @@ func (ie *IfExpression) String() string {
 }
 
 type CallExpression struct {
-	Token     token.Token
-	Function  Expression
-	Arguments []Expression
+	Token          token.Token
+	Function       Expression
+	Arguments      []Expression
+	NamedArguments []*NamedArgument //the arguments passed by name, after the positional ones
+}
+
+// NamedArgument is an argument passed by the name of its parameter, e.g.: color: "red"
+type NamedArgument struct {
+	Name  *Identifier
+	Value Expression
+}
+
+func (na *NamedArgument) String() string {
+	return na.Name.String() + ": " + na.Value.String()
 }
 
 func (ce *CallExpression) expressionNode()      {}
 func (ce *CallExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *CallExpression) Pos() token.Position  { return ce.Function.Pos() }
 func (ce *CallExpression) String() string {
 	var out bytes.Buffer
 
@@ -334,6 +439,9 @@ func (ce *CallExpression) String() string {
 	for _, a := range ce.Arguments {
 		args = append(args, a.String())
 	}
+	for _, a := range ce.NamedArguments {
+		args = append(args, a.String())
+	}
 
 	out.WriteString(ce.Function.String())
 	out.WriteString("(")
@@ -350,8 +458,72 @@ type StringLiteral struct {
 
 func (sl *StringLiteral) expressionNode()      {}
 func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *StringLiteral) Pos() token.Position  { return sl.Token.Pos }
 func (sl *StringLiteral) String() string       { return sl.Token.Literal }
 
+// TemplateLiteral is a string with embedded expressions, e.g.: "Hello ${name}!".
+// Parts alternates between the StringLiterals of the text and the embedded expressions.
+type TemplateLiteral struct {
+	Token token.Token //the first TEMPLATE token
+	Parts []Expression
+}
+
+func (tl *TemplateLiteral) expressionNode()      {}
+func (tl *TemplateLiteral) TokenLiteral() string { return tl.Token.Literal }
+func (tl *TemplateLiteral) Pos() token.Position  { return tl.Token.Pos }
+func (tl *TemplateLiteral) String() string {
+	var out bytes.Buffer
+	out.WriteString("\"")
+	for _, part := range tl.Parts {
+		if text, ok := part.(*StringLiteral); ok {
+			out.WriteString(text.Value)
+		} else {
+			out.WriteString("${" + part.String() + "}")
+		}
+	}
+	out.WriteString("\"")
+	return out.String()
+}
+
+// MatchExpression evaluates the body of the first arm whose pattern matches the value, e.g.:
+// match (x) { 1 | 2 => "small", [a, b] if a > b => a, _ => "other" }
+type MatchExpression struct {
+	Token token.Token //the MATCH token
+	Value Expression
+	Arms  []*MatchArm
+}
+
+func (me *MatchExpression) expressionNode()      {}
+func (me *MatchExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MatchExpression) Pos() token.Position  { return me.Token.Pos }
+func (me *MatchExpression) String() string {
+	arms := []string{}
+	for _, arm := range me.Arms {
+		arms = append(arms, arm.String())
+	}
+	return "match (" + me.Value.String() + ") { " + strings.Join(arms, ", ") + " }"
+}
+
+// MatchArm is one case of a match, Patterns are its alternatives. A pattern is a literal,
+// an ArrayLiteral of patterns, the _ wildcard or an Identifier that binds the value.
+type MatchArm struct {
+	Patterns []Expression
+	Guard    Expression //the condition after if, nil when there is none
+	Body     Node       //a BlockStatement or an Expression
+}
+
+func (ma *MatchArm) String() string {
+	patterns := []string{}
+	for _, pattern := range ma.Patterns {
+		patterns = append(patterns, pattern.String())
+	}
+	out := strings.Join(patterns, " | ")
+	if ma.Guard != nil {
+		out += " if " + ma.Guard.String()
+	}
+	return out + " => " + ma.Body.String()
+}
+
 type ArrayLiteral struct {
 	Token    token.Token
 	Elements []Expression
@@ -359,6 +531,7 @@ type ArrayLiteral struct {
 
 func (al *ArrayLiteral) expressionNode()      {}
 func (al *ArrayLiteral) TokenLiteral() string { return al.Token.Literal }
+func (al *ArrayLiteral) Pos() token.Position  { return al.Token.Pos }
 func (al *ArrayLiteral) String() string {
 	var out bytes.Buffer
 
@@ -383,6 +556,7 @@ type IndexExpression struct {
 
 func (ie *IndexExpression) expressionNode()      {}
 func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) Pos() token.Position  { return ie.Left.Pos() }
 func (ie *IndexExpression) String() string {
 	var out bytes.Buffer
 
@@ -394,3 +568,401 @@ func (ie *IndexExpression) String() string {
 
 	return out.String()
 }
+
+type HashLiteral struct {
+	Token token.Token //the '{' token
+	Keys  []Expression
+	Pairs map[Expression]Expression
+}
+
+func (hl *HashLiteral) expressionNode()      {}
+func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
+func (hl *HashLiteral) Pos() token.Position  { return hl.Token.Pos }
+func (hl *HashLiteral) String() string {
+	var out bytes.Buffer
+
+	pairs := []string{}
+	for _, key := range hl.Keys {
+		pairs = append(pairs, key.String()+":"+hl.Pairs[key].String())
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// LOOPS :|
+type WhileStatement struct {
+	Token     token.Token //the WHILE token
+	Condition Expression
+	Block     *BlockStatement
+}
+
+func (ws *WhileStatement) statementNode()       {}
+func (ws *WhileStatement) TokenLiteral() string { return ws.Token.Literal }
+func (ws *WhileStatement) Pos() token.Position  { return ws.Token.Pos }
+func (ws *WhileStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("while")
+	out.WriteString(ws.Condition.String())
+	out.WriteString(" ")
+	out.WriteString(ws.Block.String())
+
+	return out.String()
+}
+
+type DoWhileStatement struct {
+	Token     token.Token //the DO token
+	Block     *BlockStatement
+	Condition Expression
+}
+
+func (dw *DoWhileStatement) statementNode()       {}
+func (dw *DoWhileStatement) TokenLiteral() string { return dw.Token.Literal }
+func (dw *DoWhileStatement) Pos() token.Position  { return dw.Token.Pos }
+func (dw *DoWhileStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("do ")
+	out.WriteString(dw.Block.String())
+	out.WriteString(" while")
+	out.WriteString(dw.Condition.String())
+	out.WriteString(";")
+
+	return out.String()
+}
+
+type BreakStatement struct {
+	Token token.Token //the BREAK token
+}
+
+func (bs *BreakStatement) statementNode()       {}
+func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BreakStatement) Pos() token.Position  { return bs.Token.Pos }
+func (bs *BreakStatement) String() string       { return bs.Token.Literal + ";" }
+
+type ContinueStatement struct {
+	Token token.Token //the CONTINUE token
+}
+
+func (cs *ContinueStatement) statementNode()       {}
+func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ContinueStatement) Pos() token.Position  { return cs.Token.Pos }
+func (cs *ContinueStatement) String() string       { return cs.Token.Literal + ";" }
+
+type ForStatement struct {
+	Token     token.Token //the FOR token
+	Init      Statement   //may be nil
+	Condition Expression  //may be nil, the loop then runs until a break or return
+	Update    Expression  //may be nil
+	Block     *BlockStatement
+}
+
+func (fs *ForStatement) statementNode()       {}
+func (fs *ForStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *ForStatement) Pos() token.Position  { return fs.Token.Pos }
+func (fs *ForStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for (")
+	if fs.Init != nil {
+		out.WriteString(strings.TrimSuffix(fs.Init.String(), ";"))
+	}
+	out.WriteString("; ")
+	if fs.Condition != nil {
+		out.WriteString(fs.Condition.String())
+	}
+	out.WriteString("; ")
+	if fs.Update != nil {
+		out.WriteString(fs.Update.String())
+	}
+	out.WriteString(") ")
+	out.WriteString(fs.Block.String())
+
+	return out.String()
+}
+
+type ForeachStatement struct {
+	Token      token.Token //the FOREACH token
+	Variable   *Identifier
+	Collection Expression
+	Block      *BlockStatement
+}
+
+func (fs *ForeachStatement) statementNode()       {}
+func (fs *ForeachStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *ForeachStatement) Pos() token.Position  { return fs.Token.Pos }
+func (fs *ForeachStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("foreach (")
+	out.WriteString(fs.Variable.String())
+	out.WriteString(" in ")
+	out.WriteString(fs.Collection.String())
+	out.WriteString(") ")
+	out.WriteString(fs.Block.String())
+
+	return out.String()
+}
+
+// ASSIGNMENTS :=
+type AssignExpression struct {
+	Token    token.Token //the operator token, e.g.: "=", "+="
+	Target   Expression  //an *Identifier or an *IndexExpression
+	Operator string
+	Value    Expression
+}
+
+func (ae *AssignExpression) expressionNode()      {}
+func (ae *AssignExpression) TokenLiteral() string { return ae.Token.Literal }
+func (ae *AssignExpression) Pos() token.Position  { return ae.Target.Pos() }
+func (ae *AssignExpression) String() string {
+	return ae.Target.String() + " " + ae.Operator + " " + ae.Value.String()
+}
+
+type IncrementExpression struct {
+	Token    token.Token //the "++" or "--" token
+	Target   Expression  //an *Identifier or an *IndexExpression
+	Operator string
+	Prefix   bool //++x results in the new value, x++ in the old one
+}
+
+func (ie *IncrementExpression) expressionNode()      {}
+func (ie *IncrementExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IncrementExpression) Pos() token.Position {
+	if ie.Prefix {
+		return ie.Token.Pos
+	}
+	return ie.Target.Pos()
+}
+func (ie *IncrementExpression) String() string {
+	if ie.Prefix {
+		return "(" + ie.Operator + ie.Target.String() + ")"
+	}
+	return "(" + ie.Target.String() + ie.Operator + ")"
+}
+
+// STRUCTS {}
+type StructField struct {
+	Access   string
+	Name     *Identifier
+	Type     *TypeAnnotation
+	Embedded bool //struct Dog { Animal } embeds Animal, the field is named after its type
+}
+
+func (sf *StructField) String() string {
+	var out bytes.Buffer
+	if sf.Access != "" {
+		out.WriteString(sf.Access + " ")
+	}
+	out.WriteString(sf.Name.String())
+	if !sf.Embedded {
+		out.WriteString(":" + sf.Type.String())
+	}
+	return out.String()
+}
+
+type StructStatement struct {
+	Token  token.Token //the STRUCT token
+	Access string
+	Doc    string //the comment right above the declaration
+	Name   *Identifier
+	Fields []*StructField
+}
+
+func (ss *StructStatement) statementNode()       {}
+func (ss *StructStatement) TokenLiteral() string { return ss.Token.Literal }
+func (ss *StructStatement) Pos() token.Position  { return ss.Token.Pos }
+func (ss *StructStatement) String() string {
+	var out bytes.Buffer
+
+	fields := []string{}
+	for _, field := range ss.Fields {
+		fields = append(fields, field.String())
+	}
+
+	if ss.Access != "" {
+		out.WriteString(ss.Access + " ")
+	}
+	out.WriteString(ss.TokenLiteral() + " ")
+	out.WriteString(ss.Name.String())
+	out.WriteString(" { ")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+type StructLiteral struct {
+	Token  token.Token //the '{' token
+	Module *Identifier //the module alias of geo.Point{}, nil for structs of the current file
+	Name   *Identifier
+	Fields []*Identifier
+	Values []Expression
+}
+
+func (sl *StructLiteral) expressionNode()      {}
+func (sl *StructLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *StructLiteral) Pos() token.Position {
+	if sl.Module != nil {
+		return sl.Module.Pos()
+	}
+	return sl.Name.Pos()
+}
+func (sl *StructLiteral) String() string {
+	var out bytes.Buffer
+
+	fields := []string{}
+	for i, field := range sl.Fields {
+		fields = append(fields, field.String()+": "+sl.Values[i].String())
+	}
+
+	if sl.Module != nil {
+		out.WriteString(sl.Module.String() + ".")
+	}
+	out.WriteString(sl.Name.String())
+	out.WriteString("{")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+type MemberExpression struct {
+	Token    token.Token //the '.' token
+	Object   Expression
+	Property *Identifier
+}
+
+func (me *MemberExpression) expressionNode()      {}
+func (me *MemberExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MemberExpression) Pos() token.Position  { return me.Object.Pos() }
+func (me *MemberExpression) String() string {
+	return me.Object.String() + "." + me.Property.String()
+}
+
+// INTERFACES {}
+type InterfaceMethod struct {
+	Name       *Identifier
+	Parameters []*FunctionParameter
+	ReturnType *TypeAnnotation
+}
+
+func (im *InterfaceMethod) String() string {
+	parameters := []string{}
+	for _, param := range im.Parameters {
+		parameters = append(parameters, param.String())
+	}
+
+	signature := im.Name.String() + "(" + strings.Join(parameters, ", ") + ")"
+	if im.ReturnType != nil {
+		signature += ":" + im.ReturnType.String()
+	}
+	return signature
+}
+
+type InterfaceStatement struct {
+	Token   token.Token //the INTERFACE token
+	Access  string
+	Doc     string //the comment right above the declaration
+	Name    *Identifier
+	Methods []*InterfaceMethod
+}
+
+func (is *InterfaceStatement) statementNode()       {}
+func (is *InterfaceStatement) TokenLiteral() string { return is.Token.Literal }
+func (is *InterfaceStatement) Pos() token.Position  { return is.Token.Pos }
+func (is *InterfaceStatement) String() string {
+	var out bytes.Buffer
+
+	methods := []string{}
+	for _, method := range is.Methods {
+		methods = append(methods, method.String())
+	}
+
+	if is.Access != "" {
+		out.WriteString(is.Access + " ")
+	}
+	out.WriteString(is.TokenLiteral() + " ")
+	out.WriteString(is.Name.String())
+	out.WriteString(" { ")
+	out.WriteString(strings.Join(methods, ", "))
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+// INCLUDES #add "path"
+type IncludeStatement struct {
+	Token   token.Token //the '#add' token
+	Path    string
+	Program *Program //the included file, set by the loader
+}
+
+func (is *IncludeStatement) statementNode()       {}
+func (is *IncludeStatement) TokenLiteral() string { return is.Token.Literal }
+func (is *IncludeStatement) Pos() token.Position  { return is.Token.Pos }
+func (is *IncludeStatement) String() string {
+	return is.TokenLiteral() + " \"" + is.Path + "\""
+}
+
+// IMPORTS import "path" as alias
+type ImportStatement struct {
+	Token   token.Token //the IMPORT token
+	Path    string
+	Alias   *Identifier
+	Program *Program //the imported module, set by the loader
+}
+
+func (is *ImportStatement) statementNode()       {}
+func (is *ImportStatement) TokenLiteral() string { return is.Token.Literal }
+func (is *ImportStatement) Pos() token.Position  { return is.Token.Pos }
+func (is *ImportStatement) String() string {
+	return is.TokenLiteral() + " \"" + is.Path + "\" as " + is.Alias.String() + ";"
+}
+
+// THROW AND TRY :o
+type ThrowStatement struct {
+	Token token.Token //the THROW token
+	Value Expression
+}
+
+func (ts *ThrowStatement) statementNode()       {}
+func (ts *ThrowStatement) TokenLiteral() string { return ts.Token.Literal }
+func (ts *ThrowStatement) Pos() token.Position  { return ts.Token.Pos }
+func (ts *ThrowStatement) String() string {
+	return "throw " + ts.Value.String() + ";"
+}
+
+// TryStatement is try { } catch (e) { } finally { }, one of Catch and Finally can be nil.
+type TryStatement struct {
+	Token        token.Token //the TRY token
+	Block        *BlockStatement
+	CatchName    *Identifier //the name the caught error is bound to, nil for catch { }
+	CatchBlock   *BlockStatement
+	FinallyBlock *BlockStatement
+}
+
+func (ts *TryStatement) statementNode()       {}
+func (ts *TryStatement) TokenLiteral() string { return ts.Token.Literal }
+func (ts *TryStatement) Pos() token.Position  { return ts.Token.Pos }
+func (ts *TryStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("try { " + ts.Block.String() + " }")
+	if ts.CatchBlock != nil {
+		out.WriteString(" catch ")
+		if ts.CatchName != nil {
+			out.WriteString("(" + ts.CatchName.String() + ") ")
+		}
+		out.WriteString("{ " + ts.CatchBlock.String() + " }")
+	}
+	if ts.FinallyBlock != nil {
+		out.WriteString(" finally { " + ts.FinallyBlock.String() + " }")
+	}
+
+	return out.String()
+}
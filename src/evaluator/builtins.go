@@ -5,23 +5,26 @@ import (
 	"math/rand"
 	"sort"
 	"strconv"
+	"unicode/utf8"
 )
 
 var builtins = map[string]*object.Builtin{
 	"len": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("Compile Error: len() function can only have 1 argument")
+				return newKindError(object.TYPE_ERROR, "Compile Error: len() function can only have 1 argument")
 			}
 
 			switch arg := args[0].(type) {
 			case *object.Array:
 				return &object.Integer{Value: int64(len(arg.Elements))}
 			case *object.String:
-				return &object.Integer{Value: int64(len(arg.Value))}
+				return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+			case *object.Hash:
+				return &object.Integer{Value: int64(len(arg.Order))}
 
 			default:
-				return newError("argument to `len` not supported, got %s", args[0].Type())
+				return newKindError(object.TYPE_ERROR, "argument to `len` not supported, got %s", args[0].Type())
 			}
 		},
 	},
@@ -29,19 +32,19 @@ var builtins = map[string]*object.Builtin{
 	"Atoi": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("Compile Error: `Atoi` function can only have 1 argument")
+				return newKindError(object.TYPE_ERROR, "Compile Error: `Atoi` function can only have 1 argument")
 			}
 
 			if arg, ok := args[0].(*object.String); ok {
 				str := arg.Value
 				int64Value, err := strconv.ParseInt(str, 10, 64)
 				if err != nil {
-					return newError("Error Parsing string to int64: %s", err.Error())
+					return newKindError(object.VALUE_ERROR, "Error Parsing string to int64: %s", err.Error())
 				}
 				return &object.Integer{Value: int64Value}
 			}
 
-			return newError("Compile Error: Argument to `Atoi` must be a STRING, got %s", args[0].Type())
+			return newKindError(object.TYPE_ERROR, "Compile Error: Argument to `Atoi` must be a STRING, got %s", args[0].Type())
 
 		},
 	},
@@ -49,7 +52,7 @@ var builtins = map[string]*object.Builtin{
 	"first": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("Compile error: `first` function can only have 1 argument")
+				return newKindError(object.TYPE_ERROR, "Compile error: `first` function can only have 1 argument")
 			}
 			switch arg := args[0].(type) {
 			case *object.Array:
@@ -57,12 +60,11 @@ var builtins = map[string]*object.Builtin{
 					return arg.Elements[0]
 				}
 			case *object.String:
-				str := arg.Value
-				if len(str) > 0 {
-					return &object.String{Value: string(str[0])}
+				if ch, size := utf8.DecodeRuneInString(arg.Value); size > 0 {
+					return &object.String{Value: string(ch)}
 				}
 			default:
-				return newError("argument to `first` must be ARRAY or STRING, got %s", args[0].Type())
+				return newKindError(object.TYPE_ERROR, "argument to `first` must be ARRAY or STRING, got %s", args[0].Type())
 			}
 
 			return NULL
@@ -70,14 +72,60 @@ var builtins = map[string]*object.Builtin{
 		},
 	},
 
+	"chars": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newKindError(object.TYPE_ERROR, "Compile error: `chars` function can only have 1 argument")
+			}
+			arg, ok := args[0].(*object.String)
+			if !ok {
+				return newKindError(object.TYPE_ERROR, "argument to `chars` must be STRING, got %s", args[0].Type())
+			}
+			return &object.Array{Elements: append([]object.Object{}, arg.Items()...)}
+		},
+	},
+
+	"ord": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newKindError(object.TYPE_ERROR, "Compile error: `ord` function can only have 1 argument")
+			}
+			arg, ok := args[0].(*object.String)
+			if !ok {
+				return newKindError(object.TYPE_ERROR, "argument to `ord` must be STRING, got %s", args[0].Type())
+			}
+			if utf8.RuneCountInString(arg.Value) != 1 {
+				return newKindError(object.VALUE_ERROR, "argument to `ord` must be a single character, got %q", arg.Value)
+			}
+			ch, _ := utf8.DecodeRuneInString(arg.Value)
+			return &object.Integer{Value: int64(ch)}
+		},
+	},
+
+	"chr": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newKindError(object.TYPE_ERROR, "Compile error: `chr` function can only have 1 argument")
+			}
+			arg, ok := args[0].(*object.Integer)
+			if !ok {
+				return newKindError(object.TYPE_ERROR, "argument to `chr` must be INTEGER, got %s", args[0].Type())
+			}
+			if arg.Value > utf8.MaxRune || !utf8.ValidRune(rune(arg.Value)) {
+				return newKindError(object.VALUE_ERROR, "argument to `chr` is not a unicode code point: %d", arg.Value)
+			}
+			return &object.String{Value: string(rune(arg.Value))}
+		},
+	},
+
 	"last": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("Compile error: `last` function can only have 1 argument")
+				return newKindError(object.TYPE_ERROR, "Compile error: `last` function can only have 1 argument")
 			}
 
 			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("argument to `last` must be ARRAY, got %s", args[0].Type())
+				return newKindError(object.TYPE_ERROR, "argument to `last` must be ARRAY, got %s", args[0].Type())
 			}
 
 			arr := args[0].(*object.Array)
@@ -92,11 +140,11 @@ var builtins = map[string]*object.Builtin{
 	"rest": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("Compile error: `rest` function can only have 1 argument")
+				return newKindError(object.TYPE_ERROR, "Compile error: `rest` function can only have 1 argument")
 			}
 
 			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("argument to `rest` must be ARRAY, got %s", args[0].Type())
+				return newKindError(object.TYPE_ERROR, "argument to `rest` must be ARRAY, got %s", args[0].Type())
 			}
 			arr := args[0].(*object.Array)
 			length := len(arr.Elements)
@@ -112,10 +160,10 @@ var builtins = map[string]*object.Builtin{
 	"push": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
 			if len(args) != 2 {
-				return newError("Compile error: `push` function must have 2 arguments")
+				return newKindError(object.TYPE_ERROR, "Compile error: `push` function must have 2 arguments")
 			}
 			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("argument to `push` must be ARRAY, got %s", args[0].Type())
+				return newKindError(object.TYPE_ERROR, "argument to `push` must be ARRAY, got %s", args[0].Type())
 			}
 			arr := args[0].(*object.Array)
 			/*length := len(arr.Elements)
@@ -131,18 +179,18 @@ var builtins = map[string]*object.Builtin{
 	"randInt": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
 			if len(args) != 2 {
-				return newError("Compile error: `randInt` function must have 2 arguments")
+				return newKindError(object.TYPE_ERROR, "Compile error: `randInt` function must have 2 arguments")
 			}
 
 			if args[0].Type() != object.INTEGER_OBJ || args[1].Type() != object.INTEGER_OBJ {
-				return newError("arguments to `random` must be INTEGER, got %s and %s", args[0].Type(), args[1].Type())
+				return newKindError(object.TYPE_ERROR, "arguments to `random` must be INTEGER, got %s and %s", args[0].Type(), args[1].Type())
 			}
 
 			min := args[0].(*object.Integer).Value
 			max := args[1].(*object.Integer).Value
 
 			if min >= max {
-				return newError("min value must be less than max value")
+				return newKindError(object.VALUE_ERROR, "min value must be less than max value")
 			}
 
 			// Generate a random number between min and max
@@ -155,11 +203,11 @@ var builtins = map[string]*object.Builtin{
 	"randPick": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("Compile error: `randomElement` function must have 1 argument")
+				return newKindError(object.TYPE_ERROR, "Compile error: `randomElement` function must have 1 argument")
 			}
 
 			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("argument to `randomElement` must be ARRAY, got %s", args[0].Type())
+				return newKindError(object.TYPE_ERROR, "argument to `randomElement` must be ARRAY, got %s", args[0].Type())
 			}
 
 			arr := args[0].(*object.Array)
@@ -178,20 +226,20 @@ var builtins = map[string]*object.Builtin{
 	"sort": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("Compile Error: `sort` function must have 1 argument")
+				return newKindError(object.TYPE_ERROR, "Compile Error: `sort` function must have 1 argument")
 			}
 
 			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("argument to `sort` must be ARRAY, got %s", args[0].Type())
+				return newKindError(object.TYPE_ERROR, "argument to `sort` must be ARRAY, got %s", args[0].Type())
 			}
 
 			arr := args[0].(*object.Array)
 			elements := arr.Elements
 
-			// Check if the elements are either integers or strings
+			// Check if the elements are either numbers or strings
 			for _, element := range elements {
-				if element.Type() != object.INTEGER_OBJ && element.Type() != object.STRING_OBJ {
-					return newError("elements in the array must be INTEGER or STRING, got %s", element.Type())
+				if !isNumeric(element) && element.Type() != object.STRING_OBJ {
+					return newKindError(object.TYPE_ERROR, "elements in the array must be INTEGER, FLOAT or STRING, got %s", element.Type())
 				}
 			}
 
@@ -199,6 +247,8 @@ var builtins = map[string]*object.Builtin{
 			sort.Slice(elements, func(i, j int) bool {
 				if elements[i].Type() == object.INTEGER_OBJ && elements[j].Type() == object.INTEGER_OBJ {
 					return elements[i].(*object.Integer).Value < elements[j].(*object.Integer).Value
+				} else if isNumeric(elements[i]) && isNumeric(elements[j]) {
+					return toFloat(elements[i]) < toFloat(elements[j])
 				} else if elements[i].Type() == object.STRING_OBJ && elements[j].Type() == object.STRING_OBJ {
 					return elements[i].(*object.String).Value < elements[j].(*object.String).Value
 				}
@@ -208,4 +258,83 @@ var builtins = map[string]*object.Builtin{
 			return arr // Sorted array
 		},
 	},
+
+	"keys": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newKindError(object.TYPE_ERROR, "Compile error: `keys` function can only have 1 argument")
+			}
+
+			if args[0].Type() != object.HASH_OBJ {
+				return newKindError(object.TYPE_ERROR, "argument to `keys` must be HASH, got %s", args[0].Type())
+			}
+
+			hash := args[0].(*object.Hash)
+			keys := make([]object.Object, 0, len(hash.Order))
+			for _, key := range hash.Order {
+				keys = append(keys, hash.Pairs[key].Key)
+			}
+			return &object.Array{Elements: keys}
+		},
+	},
+
+	"values": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newKindError(object.TYPE_ERROR, "Compile error: `values` function can only have 1 argument")
+			}
+
+			if args[0].Type() != object.HASH_OBJ {
+				return newKindError(object.TYPE_ERROR, "argument to `values` must be HASH, got %s", args[0].Type())
+			}
+
+			hash := args[0].(*object.Hash)
+			values := make([]object.Object, 0, len(hash.Order))
+			for _, key := range hash.Order {
+				values = append(values, hash.Pairs[key].Value)
+			}
+			return &object.Array{Elements: values}
+		},
+	},
+
+	"has": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newKindError(object.TYPE_ERROR, "Compile error: `has` function must have 2 arguments")
+			}
+
+			if args[0].Type() != object.HASH_OBJ {
+				return newKindError(object.TYPE_ERROR, "argument to `has` must be HASH, got %s", args[0].Type())
+			}
+
+			key, ok := args[1].(object.Hashable)
+			if !ok {
+				return newKindError(object.TYPE_ERROR, "unusable as hash key: %s", args[1].Type())
+			}
+
+			_, ok = args[0].(*object.Hash).Get(key)
+			return nativeBoolToBooleanObject(ok)
+		},
+	},
+
+	"delete": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newKindError(object.TYPE_ERROR, "Compile error: `delete` function must have 2 arguments")
+			}
+
+			if args[0].Type() != object.HASH_OBJ {
+				return newKindError(object.TYPE_ERROR, "argument to `delete` must be HASH, got %s", args[0].Type())
+			}
+
+			key, ok := args[1].(object.Hashable)
+			if !ok {
+				return newKindError(object.TYPE_ERROR, "unusable as hash key: %s", args[1].Type())
+			}
+
+			hash := args[0].(*object.Hash)
+			hash.Delete(key)
+			return hash
+		},
+	},
 }
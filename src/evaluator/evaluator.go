@@ -3,18 +3,28 @@ package evaluator
 import (
 	"Goslang/ast"
 	"Goslang/object"
+	"Goslang/token"
 	"fmt"
 	"math"
+	"sort"
+	"strings"
 )
 
 var (
-	TRUE  = &object.Boolean{Value: true}
-	FALSE = &object.Boolean{Value: false}
-	NULL  = &object.Null{}
+	TRUE     = &object.Boolean{Value: true}
+	FALSE    = &object.Boolean{Value: false}
+	NULL     = &object.Null{}
+	BREAK    = &object.Break{}
+	CONTINUE = &object.Continue{}
 )
 
 func newError(format string, a ...interface{}) *object.Error {
-	return &object.Error{Message: fmt.Sprintf(format, a...)}
+	return newKindError(object.RUNTIME_ERROR, format, a...)
+}
+
+// newKindError returns an error of the given kind, e.g.: object.TYPE_ERROR
+func newKindError(kind string, format string, a ...interface{}) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(format, a...), Kind: kind}
 }
 
 func isError(obj object.Object) bool {
@@ -24,7 +34,16 @@ func isError(obj object.Object) bool {
 	return false
 }
 
+// Eval evaluates node in env, errors are given the position of the innermost node they came from.
 func Eval(node ast.Node, env *object.Environment) object.Object {
+	result := evalNode(node, env)
+	if err, ok := result.(*object.Error); ok && !err.Pos.IsValid() {
+		err.Pos = node.Pos()
+	}
+	return result
+}
+
+func evalNode(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
 
 	//Statements
@@ -39,7 +58,7 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if isError(val) {
 			return val
 		}
-		env.Set(node.Name.Value, val)
+		declare(env, node.Access, node.Name.Value, val)
 
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
@@ -52,17 +71,30 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return &object.ReturnVal{Value: val}
 
 	case *ast.FunctionStatement:
+		if node.Receiver != nil {
+			return evalMethodStatement(node, env)
+		}
 		params := node.Parameters
 		block := node.Block
 
 		fnobj := &object.Function{
+			Name:       node.Name,
 			Parameters: params,
+			ReturnType: node.ReturnType,
 			Block:      block,
 			Env:        env,
 		}
-		env.Set(node.Name.Value, fnobj)
+		declare(env, node.Access, node.Name.Value, fnobj)
 		return fnobj
 
+	case *ast.FunctionLiteral:
+		return &object.Function{
+			Parameters: node.Parameters,
+			ReturnType: node.ReturnType,
+			Block:      node.Block,
+			Env:        env,
+		}
+
 	case *ast.CallExpression:
 		function := Eval(node.Function, env)
 		if isError(function) {
@@ -72,7 +104,11 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if len(args) == 1 && isError(args[0]) {
 			return args[0]
 		}
-		return applyFunction(function, args)
+		named, err := evalNamedArguments(node.NamedArguments, function, env)
+		if err != nil {
+			return err
+		}
+		return applyFunction(function, args, named, node.Function.Pos())
 
 	case *ast.ArrayLiteral:
 		elements := evalExpressions(node.Elements, env)
@@ -81,19 +117,55 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 		return &object.Array{Elements: elements}
 
+	case *ast.HashLiteral:
+		return evalHashLiteral(node, env)
+
 		// Expressions
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
 
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
+
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
 
+	case *ast.TemplateLiteral:
+		return evalTemplateLiteral(node, env)
+
 	case *ast.BlockStatement:
 		return evalBlockStatement(node, env)
 
 	case *ast.IfExpression:
 		return evalIfExpression(node, env)
 
+	case *ast.MatchExpression:
+		return evalMatchExpression(node, env)
+
+	case *ast.WhileStatement:
+		return evalWhileStatement(node, env)
+
+	case *ast.DoWhileStatement:
+		return evalDoWhileStatement(node, env)
+
+	case *ast.ForStatement:
+		return evalForStatement(node, env)
+
+	case *ast.ForeachStatement:
+		return evalForeachStatement(node, env)
+
+	case *ast.ThrowStatement:
+		return evalThrowStatement(node, env)
+
+	case *ast.TryStatement:
+		return evalTryStatement(node, env)
+
+	case *ast.BreakStatement:
+		return BREAK
+
+	case *ast.ContinueStatement:
+		return CONTINUE
+
 	case *ast.Boolean:
 		return nativeBoolToBooleanObject(node.Value)
 
@@ -105,6 +177,9 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return evalPrefixExpression(node.Operator, right)
 
 	case *ast.InfixExpression:
+		if node.Operator == "&&" || node.Operator == "||" {
+			return evalLogicalExpression(node, env)
+		}
 		left := Eval(node.Left, env)
 		if isError(left) {
 			return left
@@ -116,6 +191,35 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 
 		return evalInfixExpression(node.Operator, left, right)
 
+	case *ast.StructStatement:
+		return evalStructStatement(node, env)
+
+	case *ast.IncludeStatement:
+		return evalIncludeStatement(node, env)
+
+	case *ast.ImportStatement:
+		return evalImportStatement(node, env)
+
+	case *ast.StructLiteral:
+		return evalStructLiteral(node, env)
+
+	case *ast.InterfaceStatement:
+		declare(env, node.Access, node.Name.Value, &object.Interface{Name: node.Name.Value, Methods: node.Methods})
+		return nil
+
+	case *ast.MemberExpression:
+		obj := Eval(node.Object, env)
+		if isError(obj) {
+			return obj
+		}
+		return evalMemberExpression(obj, node.Property.Value, env)
+
+	case *ast.AssignExpression:
+		return evalAssignExpression(node, env)
+
+	case *ast.IncrementExpression:
+		return evalIncrementExpression(node, env)
+
 	case *ast.IndexExpression:
 		left := Eval(node.Left, env)
 		if isError(left) {
@@ -167,7 +271,7 @@ func evalPrefixExpression(operator string, right object.Object) object.Object {
 		return evalBitNOTPrefixOperatorExpression(right)
 
 	default:
-		return newError("unknown operator: %s%s", operator, right.Type())
+		return newKindError(object.TYPE_ERROR, "unknown operator: %s%s", operator, right.Type())
 	}
 }
 
@@ -189,18 +293,21 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 }
 
 func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
-	if right.Type() != object.INTEGER_OBJ {
-		return newError("unknown operator: -%s", right.Type())
-	}
+	switch right := right.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: -right.Value}
 
-	value := right.(*object.Integer).Value
+	case *object.Float:
+		return &object.Float{Value: -right.Value}
 
-	return &object.Integer{Value: -value}
+	default:
+		return newKindError(object.TYPE_ERROR, "unknown operator: -%s", right.Type())
+	}
 }
 
 func evalBitNOTPrefixOperatorExpression(right object.Object) object.Object {
 	if right.Type() != object.INTEGER_OBJ {
-		return newError("unknown operator: ~%s", right.Type())
+		return newKindError(object.TYPE_ERROR, "unknown operator: ~%s", right.Type())
 	}
 
 	value := right.(*object.Integer).Value
@@ -214,6 +321,10 @@ func evalInfixExpression(operator string, left, right object.Object) object.Obje
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
 		return evalIntegerInfixExpression(operator, left, right)
 
+	//int and float mixes are promoted to float
+	case isNumeric(left) && isNumeric(right):
+		return evalFloatInfixExpression(operator, left, right)
+
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return evalStringInfixExpression(operator, left, right)
 
@@ -224,10 +335,10 @@ func evalInfixExpression(operator string, left, right object.Object) object.Obje
 		return nativeBoolToBooleanObject(left != right)
 
 	case left.Type() != right.Type():
-		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+		return newKindError(object.TYPE_ERROR, "type mismatch: %s %s %s", left.Type(), operator, right.Type())
 
 	default:
-		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+		return newKindError(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
 }
 
@@ -248,6 +359,9 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 		return &object.Integer{Value: leftVal * rightVal}
 
 	case "/":
+		if rightVal == 0 {
+			return newKindError(object.VALUE_ERROR, "division by zero")
+		}
 		return &object.Integer{Value: leftVal / rightVal}
 
 	case "^":
@@ -255,6 +369,9 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 		return &object.Integer{Value: int64(result)}
 
 	case "%":
+		if rightVal == 0 {
+			return newKindError(object.VALUE_ERROR, "division by zero")
+		}
 		return &object.Integer{Value: leftVal % rightVal}
 
 	//AND
@@ -273,6 +390,12 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 	case ">":
 		return nativeBoolToBooleanObject(leftVal > rightVal)
 
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+
 	case "==":
 		return nativeBoolToBooleanObject(leftVal == rightVal)
 
@@ -280,10 +403,95 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 		return nativeBoolToBooleanObject(leftVal != rightVal)
 
 	default:
-		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+		return newKindError(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
 }
 
+func evalFloatInfixExpression(operator string, left, right object.Object) object.Object {
+
+	leftVal := toFloat(left)
+	rightVal := toFloat(right)
+
+	switch operator {
+
+	case "+":
+		return &object.Float{Value: leftVal + rightVal}
+
+	case "-":
+		return &object.Float{Value: leftVal - rightVal}
+
+	case "*":
+		return &object.Float{Value: leftVal * rightVal}
+
+	case "/":
+		return &object.Float{Value: leftVal / rightVal}
+
+	case "^":
+		return &object.Float{Value: math.Pow(leftVal, rightVal)}
+
+	case "%":
+		return &object.Float{Value: math.Mod(leftVal, rightVal)}
+
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+
+	default:
+		return newKindError(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func isNumeric(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
+// toFloat converts an INTEGER or FLOAT object to a float64.
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value)
+	case *object.Float:
+		return obj.Value
+	}
+	return 0
+}
+
+// evalLogicalExpression evaluates && and ||. The right side is only evaluated
+// when the left side does not already decide the result.
+func evalLogicalExpression(node *ast.InfixExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+
+	if node.Operator == "&&" && !isTruthy(left) {
+		return FALSE
+	}
+	if node.Operator == "||" && isTruthy(left) {
+		return TRUE
+	}
+
+	right := Eval(node.Right, env)
+	if isError(right) {
+		return right
+	}
+	return nativeBoolToBooleanObject(isTruthy(right))
+}
+
 func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
 	condition := Eval(ie.Condition, env)
 	if isError(condition) {
@@ -299,6 +507,192 @@ func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Obje
 	}
 }
 
+// evalMatchExpression evaluates the body of the first arm with a pattern that matches the
+// value and whose guard holds. The names bound by the pattern are scoped to the arm.
+func evalMatchExpression(me *ast.MatchExpression, env *object.Environment) object.Object {
+	value := Eval(me.Value, env)
+	if isError(value) {
+		return value
+	}
+
+	for _, arm := range me.Arms {
+		for _, pattern := range arm.Patterns {
+			armEnv := object.NewEnclosedEnvironment(env)
+			matched, err := matchPattern(pattern, value, armEnv)
+			if err != nil {
+				return err
+			}
+			if !matched {
+				continue
+			}
+
+			if arm.Guard != nil {
+				guard := Eval(arm.Guard, armEnv)
+				if isError(guard) {
+					return guard
+				}
+				if !isTruthy(guard) {
+					continue
+				}
+			}
+			//a block arm that ends with a statement, e.g. a var, has no value
+			result := Eval(arm.Body, armEnv)
+			if result == nil {
+				result = NULL
+			}
+			return result
+		}
+	}
+	return newKindError(object.VALUE_ERROR, "no match arm matches %s", value.Inspect())
+}
+
+// matchPattern reports whether value matches pattern, the names the pattern binds are set in env.
+func matchPattern(pattern ast.Expression, value object.Object, env *object.Environment) (bool, *object.Error) {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		if pattern.Value != "_" {
+			env.Set(pattern.Value, value)
+		}
+		return true, nil
+
+	case *ast.ArrayLiteral:
+		array, ok := value.(*object.Array)
+		if !ok || len(array.Elements) != len(pattern.Elements) {
+			return false, nil
+		}
+		for i, element := range pattern.Elements {
+			matched, err := matchPattern(element, array.Elements[i], env)
+			if err != nil || !matched {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+
+	literal := Eval(pattern, env)
+	if err, ok := literal.(*object.Error); ok {
+		return false, err
+	}
+	switch literal := literal.(type) {
+	case *object.String:
+		str, ok := value.(*object.String)
+		return ok && str.Value == literal.Value, nil
+	case *object.Boolean:
+		boolean, ok := value.(*object.Boolean)
+		return ok && boolean.Value == literal.Value, nil
+	case *object.Integer:
+		if integer, ok := value.(*object.Integer); ok {
+			return integer.Value == literal.Value, nil
+		}
+	}
+	//an int pattern matches the equal float and the other way around
+	return isNumeric(literal) && isNumeric(value) && toFloat(literal) == toFloat(value), nil
+}
+
+func evalWhileStatement(ws *ast.WhileStatement, env *object.Environment) object.Object {
+	for {
+		condition := Eval(ws.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+		if !isTruthy(condition) {
+			return nil
+		}
+
+		if result, done := evalLoopBody(ws.Block, env); done {
+			return result
+		}
+	}
+}
+
+func evalDoWhileStatement(dw *ast.DoWhileStatement, env *object.Environment) object.Object {
+	for {
+		if result, done := evalLoopBody(dw.Block, env); done {
+			return result
+		}
+
+		condition := Eval(dw.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+		if !isTruthy(condition) {
+			return nil
+		}
+	}
+}
+
+func evalForStatement(fs *ast.ForStatement, env *object.Environment) object.Object {
+	loopEnv := object.NewEnclosedEnvironment(env)
+
+	if fs.Init != nil {
+		init := Eval(fs.Init, loopEnv)
+		if isError(init) {
+			return init
+		}
+	}
+
+	for {
+		if fs.Condition != nil {
+			condition := Eval(fs.Condition, loopEnv)
+			if isError(condition) {
+				return condition
+			}
+			if !isTruthy(condition) {
+				return nil
+			}
+		}
+
+		if result, done := evalLoopBody(fs.Block, loopEnv); done {
+			return result
+		}
+
+		if fs.Update != nil {
+			update := Eval(fs.Update, loopEnv)
+			if isError(update) {
+				return update
+			}
+		}
+	}
+}
+
+func evalForeachStatement(fs *ast.ForeachStatement, env *object.Environment) object.Object {
+	collection := Eval(fs.Collection, env)
+	if isError(collection) {
+		return collection
+	}
+
+	iterable, ok := collection.(object.Iterable)
+	if !ok {
+		return newKindError(object.TYPE_ERROR, "foreach not supported: %s", collection.Type())
+	}
+
+	for _, item := range iterable.Items() {
+		iterationEnv := object.NewEnclosedEnvironment(env)
+		iterationEnv.Set(fs.Variable.Value, item)
+
+		if result, done := evalLoopBody(fs.Block, iterationEnv); done {
+			return result
+		}
+	}
+	return nil
+}
+
+// evalLoopBody runs one iteration of a loop block. It reports whether the loop
+// has to stop and the object the loop results in: a return value or an error
+// that must propagate, or nil after a break.
+func evalLoopBody(block *ast.BlockStatement, env *object.Environment) (object.Object, bool) {
+	result := Eval(block, env)
+	if result != nil {
+		switch result.Type() {
+		case object.RETURN_VAL_OBJ, object.ERROR_OBJ:
+			return result, true
+		case object.BREAK_OBJ:
+			return nil, true
+		}
+	}
+	return nil, false
+}
+
 func isTruthy(obj object.Object) bool {
 	switch obj {
 	case NULL:
@@ -321,14 +715,24 @@ func evalProgram(program *ast.Program, env *object.Environment) object.Object {
 	for _, statement := range program.Statements {
 		result := Eval(statement, env)
 
+		//assignments are not printed, only their errors are reported
+		if isAssignment(statement) && !isError(result) {
+			continue
+		}
+
 		switch result := result.(type) {
 
-		case *object.ReturnVal, *object.Integer, *object.String, *object.Array, *object.Boolean, *object.Null:
+		case *object.ReturnVal, *object.Integer, *object.Float, *object.String, *object.Array, *object.Hash, *object.Struct, *object.Boolean, *object.Null:
 			results = append(results, result)
 
 		case *object.Error:
 			return result
 
+		case *object.Break, *object.Continue:
+			err := newError("%s outside of a loop", result.Inspect())
+			err.Pos = statement.Pos()
+			return err
+
 			/*default:
 			return result*/
 		}
@@ -343,11 +747,8 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) obje
 	for _, statement := range block.Statements {
 		result = Eval(statement, env)
 
-		if result != nil {
-			rt := result.Type()
-			if rt == object.RETURN_VAL_OBJ || rt == object.ERROR_OBJ {
-				return result
-			}
+		if interruptsBlock(result) {
+			return result
 		}
 	}
 	return result
@@ -362,7 +763,7 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 		return builtin
 	}
 
-	return newError("identifier not found: " + node.Value)
+	return newKindError(object.NAME_ERROR, "identifier not found: "+node.Value)
 }
 
 func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
@@ -379,31 +780,297 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 	return result
 }
 
-func applyFunction(fn object.Object, args []object.Object) object.Object {
+// evalNamedArguments evaluates the arguments passed by name to fn, keyed by parameter name.
+func evalNamedArguments(arguments []*ast.NamedArgument, fn object.Object, env *object.Environment) (map[string]object.Object, *object.Error) {
+	if len(arguments) == 0 {
+		return nil, nil
+	}
+
+	named := make(map[string]object.Object, len(arguments))
+	for _, arg := range arguments {
+		if _, ok := named[arg.Name.Value]; ok {
+			return nil, newKindError(object.TYPE_ERROR, "argument %s of %s is given more than once", arg.Name.Value, calleeName(fn))
+		}
+		value := Eval(arg.Value, env)
+		if err, ok := value.(*object.Error); ok {
+			return nil, err
+		}
+		named[arg.Name.Value] = value
+	}
+	return named, nil
+}
+
+// calleeName names fn in the errors of a call.
+func calleeName(fn object.Object) string {
+	switch fn := fn.(type) {
+	case *object.Function:
+		return functionName(fn)
+	case *object.BoundMethod:
+		return functionName(fn.Method)
+	default:
+		return fn.Inspect()
+	}
+}
+
+// evalTemplateLiteral joins the text of the template with the Inspect of each embedded expression.
+func evalTemplateLiteral(node *ast.TemplateLiteral, env *object.Environment) object.Object {
+	var out strings.Builder
+	for _, part := range node.Parts {
+		if text, ok := part.(*ast.StringLiteral); ok {
+			out.WriteString(text.Value)
+			continue
+		}
+		value := Eval(part, env)
+		if isError(value) {
+			return value
+		}
+		//an expression without a value, e.g. a call of a function that returns nothing, is written as null
+		if value == nil {
+			value = NULL
+		}
+		out.WriteString(value.Inspect())
+	}
+	return &object.String{Value: out.String()}
+}
+
+// applyFunction calls fn with the positional args and the named ones, callSite is the
+// position of the call.
+func applyFunction(fn object.Object, args []object.Object, named map[string]object.Object, callSite token.Position) object.Object {
 	switch fn := fn.(type) {
 
 	case *object.Function:
-		extendedEnv := extendFunctionEnv(fn, args)
-		evaluated := Eval(fn.Block, extendedEnv)
-		return unwrapReturnValue(evaluated)
+		extendedEnv, err := extendFunctionEnv(fn, args, named)
+		if err != nil {
+			return err
+		}
+		return callFunction(fn, extendedEnv, callSite)
+
+	case *object.BoundMethod:
+		extendedEnv, err := extendFunctionEnv(fn.Method, args, named)
+		if err != nil {
+			return err
+		}
+		extendedEnv.Set("self", fn.Receiver)
+		extendedEnv.SetReceiver(fn.Receiver)
+		return callFunction(fn.Method, extendedEnv, callSite)
 
 	case *object.Builtin:
+		if len(named) > 0 {
+			return newKindError(object.TYPE_ERROR, "builtin functions do not take named arguments")
+		}
 		return fn.Fn(args...)
 
 	default:
-		return newError("not a function: %s", fn.Type())
+		return newKindError(object.TYPE_ERROR, "not a function: %s", fn.Type())
 	}
 }
 
+// callFunction evaluates the body of fn as a call on the call stack of the run, an error
+// returned by the body gets the stack it was raised in.
+func callFunction(fn *object.Function, env *object.Environment, callSite token.Position) object.Object {
+	run := env.Run()
+	run.Stack = append(run.Stack, object.Frame{Function: functionName(fn), Pos: callSite})
+	defer func() { run.Stack = run.Stack[:len(run.Stack)-1] }()
+
+	result := evalFunctionBody(fn, env)
+	if err, ok := result.(*object.Error); ok && err.Stack == nil {
+		err.Stack = append([]object.Frame{}, run.Stack...)
+	}
+	return result
+}
+
+func evalFunctionBody(fn *object.Function, env *object.Environment) object.Object {
+	evaluated := Eval(fn.Block, env)
+	if evaluated == BREAK || evaluated == CONTINUE {
+		return newError("%s outside of a loop", evaluated.Inspect())
+	}
+	result := unwrapReturnValue(evaluated)
+	if fn.ReturnType == nil || isError(result) {
+		return result
+	}
+
+	if result == nil {
+		result = NULL
+	}
+	checked, mismatch := checkType(fn.ReturnType, result, fn.Env)
+	if mismatch != "" {
+		return newKindError(object.TYPE_ERROR, "return value of %s: %s", functionName(fn), mismatch)
+	}
+	return checked
+}
+
+// extendFunctionEnv binds the arguments of a call to the parameters of fn, the positional
+// args in order and then the named ones by parameter name. Parameters left out get their
+// default value.
 func extendFunctionEnv(
 	fn *object.Function,
 	args []object.Object,
-) *object.Environment {
+	named map[string]object.Object,
+) (*object.Environment, *object.Error) {
+	if !ast.Accepts(fn.Parameters, len(args)+len(named)) {
+		return nil, newKindError(object.TYPE_ERROR, "wrong number of arguments to %s: expected %s, got %d",
+			functionName(fn), ast.Arity(fn.Parameters), len(args)+len(named))
+	}
+	if err := checkNamedArguments(fn, len(args), named); err != nil {
+		return nil, err
+	}
+
 	env := object.NewEnclosedEnvironment(fn.Env)
 	for paramIdx, param := range fn.Parameters {
-		env.Set(param.Name.Value, args[paramIdx])
+		if param.Variadic {
+			rest := &object.Array{Elements: []object.Object{}}
+			if paramIdx < len(args) {
+				for _, arg := range args[paramIdx:] {
+					checked, err := checkArgument(fn, param, arg)
+					if err != nil {
+						return nil, err
+					}
+					rest.Elements = append(rest.Elements, checked)
+				}
+			}
+			env.Set(param.Name.Value, rest)
+			break
+		}
+
+		arg, ok := named[param.Name.Value]
+		switch {
+		case paramIdx < len(args):
+			arg = args[paramIdx]
+		case ok:
+		case param.Default != nil:
+			//the default value can use the parameters before it
+			arg = Eval(param.Default, env)
+			if err, ok := arg.(*object.Error); ok {
+				return nil, err
+			}
+		default:
+			return nil, newKindError(object.TYPE_ERROR, "missing argument %s of %s", param.Name.Value, functionName(fn))
+		}
+		checked, err := checkArgument(fn, param, arg)
+		if err != nil {
+			return nil, err
+		}
+		env.Set(param.Name.Value, checked)
 	}
-	return env
+	return env, nil
+}
+
+// checkNamedArguments verifies that every named argument matches a parameter of fn
+// that was not already given one of the positional arguments.
+func checkNamedArguments(fn *object.Function, positional int, named map[string]object.Object) *object.Error {
+	if len(named) == 0 {
+		return nil
+	}
+
+	index := make(map[string]int, len(fn.Parameters))
+	for i, param := range fn.Parameters {
+		index[param.Name.Value] = i
+	}
+	//sorted so that the error reported does not depend on map order
+	names := make([]string, 0, len(named))
+	for name := range named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		i, ok := index[name]
+		switch {
+		case !ok:
+			return newKindError(object.TYPE_ERROR, "%s has no parameter %s", functionName(fn), name)
+		case fn.Parameters[i].Variadic:
+			return newKindError(object.TYPE_ERROR, "variadic parameter %s of %s cannot be given by name", name, functionName(fn))
+		case i < positional:
+			return newKindError(object.TYPE_ERROR, "argument %s of %s is given more than once", name, functionName(fn))
+		}
+	}
+	return nil
+}
+
+// checkArgument checks arg against the type of param, ints passed as floats are converted.
+func checkArgument(fn *object.Function, param *ast.FunctionParameter, arg object.Object) (object.Object, *object.Error) {
+	if param.Type == nil {
+		return arg, nil
+	}
+	checked, mismatch := checkType(param.Type, arg, fn.Env)
+	if mismatch != "" {
+		return nil, newKindError(object.TYPE_ERROR, "argument %s of %s: %s", param.Name.Value, functionName(fn), mismatch)
+	}
+	return checked, nil
+}
+
+// builtinTypes are the object types accepted by the type annotations of the language.
+var builtinTypes = map[string][]object.ObjectType{
+	"int":    {object.INTEGER_OBJ},
+	"float":  {object.FLOAT_OBJ},
+	"string": {object.STRING_OBJ},
+	"bool":   {object.BOOLEAN_OBJ},
+	"array":  {object.ARRAY_OBJ},
+	"hash":   {object.HASH_OBJ},
+	"fn":     {object.FUNCTION_OBJ, object.BUILTIN_OBJ, object.METHOD_OBJ},
+}
+
+// checkType verifies that value matches the type annotation t, struct and interface
+// names are resolved in env. It returns the value to use, integers passed as float are
+// converted, or a description of the mismatch.
+func checkType(t *ast.TypeAnnotation, value object.Object, env *object.Environment) (object.Object, string) {
+	if t.Value == "float" && value.Type() == object.INTEGER_OBJ {
+		return &object.Float{Value: toFloat(value)}, ""
+	}
+	if accepted, ok := builtinTypes[t.Value]; ok {
+		for _, objectType := range accepted {
+			if value.Type() == objectType {
+				return value, ""
+			}
+		}
+		return nil, fmt.Sprintf("expected %s, got %s", accepted[0], typeName(value))
+	}
+
+	declared, ok := env.Get(t.Value)
+	if !ok {
+		return nil, fmt.Sprintf("unknown type %s", t.Value)
+	}
+
+	switch declared := declared.(type) {
+	case *object.StructDefinition:
+		if instance, ok := value.(*object.Struct); ok && instance.Definition == declared {
+			return value, ""
+		}
+		return nil, fmt.Sprintf("expected %s, got %s", declared.Name, typeName(value))
+
+	case *object.Interface:
+		missing := declared.Methods
+		if instance, ok := value.(*object.Struct); ok {
+			missing = declared.MissingMethods(instance.Definition)
+		}
+		if len(missing) == 0 {
+			return value, ""
+		}
+		signatures := []string{}
+		for _, method := range missing {
+			signatures = append(signatures, method.String())
+		}
+		return nil, fmt.Sprintf("%s does not implement %s (missing methods: %s)",
+			typeName(value), declared.Name, strings.Join(signatures, ", "))
+
+	default:
+		return nil, fmt.Sprintf("%s is not a type", t.Value)
+	}
+}
+
+// typeName is the type of value used in type errors, the name of the struct for struct values.
+func typeName(value object.Object) string {
+	if instance, ok := value.(*object.Struct); ok {
+		return instance.Definition.Name
+	}
+	return string(value.Type())
+}
+
+func functionName(fn *object.Function) string {
+	if fn.Name == nil {
+		return "anonymous function"
+	}
+	return fn.Name.Value
 }
 
 func unwrapReturnValue(obj object.Object) object.Object {
@@ -417,21 +1084,40 @@ func evalStringInfixExpression(
 	operator string,
 	left, right object.Object,
 ) object.Object {
-	if operator != "+" {
-		return newError("unknown operator: %s %s %s",
-			left.Type(), operator, right.Type())
-	}
 	leftVal := left.(*object.String).Value
 	rightVal := right.(*object.String).Value
-	return &object.String{Value: leftVal + rightVal}
+
+	switch operator {
+	case "+":
+		return &object.String{Value: leftVal + rightVal}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newKindError(object.TYPE_ERROR, "unknown operator: %s %s %s",
+			left.Type(), operator, right.Type())
+	}
 }
 
 func evalIndexExpression(left, index object.Object) object.Object {
 	switch {
 	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
 		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalStringIndexExpression(left, index)
+	case left.Type() == object.HASH_OBJ:
+		return evalHashIndexExpression(left, index)
 	default:
-		return newError("index operator not supported: %s", left.Type())
+		return newKindError(object.TYPE_ERROR, "index operator not supported: %s", left.Type())
 	}
 }
 
@@ -444,3 +1130,545 @@ func evalArrayIndexExpression(array, index object.Object) object.Object {
 	}
 	return arrayObject.Elements[idx]
 }
+
+// evalStringIndexExpression returns the character at index, counted in unicode characters rather than bytes.
+func evalStringIndexExpression(str, index object.Object) object.Object {
+	idx := index.(*object.Integer).Value
+	if idx < 0 {
+		return NULL
+	}
+	for _, ch := range str.(*object.String).Value {
+		if idx == 0 {
+			return &object.String{Value: string(ch)}
+		}
+		idx--
+	}
+	return NULL
+}
+
+func evalHashIndexExpression(hash, index object.Object) object.Object {
+	hashObject := hash.(*object.Hash)
+
+	key, ok := index.(object.Hashable)
+	if !ok {
+		return newKindError(object.TYPE_ERROR, "unusable as hash key: %s", index.Type())
+	}
+
+	value, ok := hashObject.Get(key)
+	if !ok {
+		return NULL
+	}
+	return value
+}
+
+func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
+	hash := object.NewHash()
+
+	for _, keyNode := range node.Keys {
+		key := Eval(keyNode, env)
+		if isError(key) {
+			return key
+		}
+
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return newKindError(object.TYPE_ERROR, "unusable as hash key: %s", key.Type())
+		}
+
+		value := Eval(node.Pairs[keyNode], env)
+		if isError(value) {
+			return value
+		}
+
+		hash.Set(hashKey, value)
+	}
+
+	return hash
+}
+
+func isAssignment(statement ast.Statement) bool {
+	if stmt, ok := statement.(*ast.ExpressionStatement); ok {
+		switch stmt.Expression.(type) {
+		case *ast.AssignExpression, *ast.IncrementExpression:
+			return true
+		}
+	}
+	return false
+}
+
+func evalAssignExpression(node *ast.AssignExpression, env *object.Environment) object.Object {
+	current, store := evalAssignTarget(node.Target, env)
+	if isError(current) {
+		return current
+	}
+
+	value := Eval(node.Value, env)
+	if isError(value) {
+		return value
+	}
+
+	if node.Operator != "=" {
+		//compound assignment, e.g.: x += 1 is x = x + 1
+		value = evalInfixExpression(strings.TrimSuffix(node.Operator, "="), current, value)
+		if isError(value) {
+			return value
+		}
+	}
+
+	return store(value)
+}
+
+func evalIncrementExpression(node *ast.IncrementExpression, env *object.Environment) object.Object {
+	current, store := evalAssignTarget(node.Target, env)
+	if isError(current) {
+		return current
+	}
+
+	if !isNumeric(current) {
+		return newKindError(object.TYPE_ERROR, "unknown operator: %s%s", current.Type(), node.Operator)
+	}
+
+	value := evalInfixExpression(node.Operator[:1], current, &object.Integer{Value: 1})
+	if result := store(value); isError(result) {
+		return result
+	}
+
+	if node.Prefix {
+		return value
+	}
+	return current
+}
+
+// evalAssignTarget resolves the target of an assignment. It returns the current
+// value of the target and a function that stores a new value into it.
+func evalAssignTarget(
+	target ast.Expression,
+	env *object.Environment,
+) (object.Object, func(object.Object) object.Object) {
+	switch target := target.(type) {
+
+	case *ast.Identifier:
+		current, ok := env.Get(target.Value)
+		if !ok {
+			return newKindError(object.NAME_ERROR, "cannot assign to undeclared variable: %s", target.Value), nil
+		}
+		return current, func(value object.Object) object.Object {
+			env.Assign(target.Value, value)
+			return value
+		}
+
+	case *ast.MemberExpression:
+		obj := Eval(target.Object, env)
+		if isError(obj) {
+			return obj, nil
+		}
+		structObj, ok := obj.(*object.Struct)
+		if !ok {
+			return newKindError(object.TYPE_ERROR, "field assignment not supported: %s", obj.Type()), nil
+		}
+		field := target.Property.Value
+		owner := findMember(structObj, field)
+		if owner == nil || !owner.Definition.HasField(field) {
+			return newKindError(object.NAME_ERROR, "unknown field %s in struct %s", field, structObj.Definition.Name), nil
+		}
+		if err := checkMemberAccess(owner.Definition, field, env); err != nil {
+			return err, nil
+		}
+		return owner.Fields[field], func(value object.Object) object.Object {
+			owner.Fields[field] = value
+			return value
+		}
+
+	case *ast.IndexExpression:
+		left := Eval(target.Left, env)
+		if isError(left) {
+			return left, nil
+		}
+		index := Eval(target.Index, env)
+		if isError(index) {
+			return index, nil
+		}
+		if left.Type() != object.ARRAY_OBJ && left.Type() != object.HASH_OBJ {
+			return newKindError(object.TYPE_ERROR, "index assignment not supported: %s", left.Type()), nil
+		}
+		current := evalIndexExpression(left, index)
+		if isError(current) {
+			return current, nil
+		}
+		return current, func(value object.Object) object.Object {
+			return evalIndexAssignment(left, index, value)
+		}
+
+	default:
+		return newError("invalid assignment target: %s", target.String()), nil
+	}
+}
+
+func evalIndexAssignment(left, index, value object.Object) object.Object {
+	switch left := left.(type) {
+
+	case *object.Array:
+		if index.Type() != object.INTEGER_OBJ {
+			return newKindError(object.TYPE_ERROR, "array index must be INTEGER, got %s", index.Type())
+		}
+		idx := index.(*object.Integer).Value
+		if idx < 0 || idx >= int64(len(left.Elements)) {
+			return newKindError(object.INDEX_ERROR, "index out of range: %d", idx)
+		}
+		left.Elements[idx] = value
+		return value
+
+	case *object.Hash:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newKindError(object.TYPE_ERROR, "unusable as hash key: %s", index.Type())
+		}
+		left.Set(key, value)
+		return value
+
+	default:
+		return newKindError(object.TYPE_ERROR, "index assignment not supported: %s", left.Type())
+	}
+}
+
+// evalFile evaluates an included or imported file in its own environment of the run,
+// a file is evaluated once per run no matter how many files refer to it.
+func evalFile(program *ast.Program, run *object.Run) (*object.Environment, object.Object) {
+	if fileEnv, ok := run.Files[program]; ok {
+		return fileEnv, nil
+	}
+
+	fileEnv := object.NewRunEnvironment(run)
+	result := Eval(program, fileEnv)
+	if isError(result) {
+		return nil, result
+	}
+	run.Files[program] = fileEnv
+	return fileEnv, nil
+}
+
+// evalIncludeStatement binds the public declarations of the included file
+// in the environment of the including file.
+func evalIncludeStatement(node *ast.IncludeStatement, env *object.Environment) object.Object {
+	if node.Program == nil {
+		return newError("include not loaded: %s", node.Path)
+	}
+
+	fileEnv, err := evalFile(node.Program, env.Run())
+	if err != nil {
+		return err
+	}
+
+	env.Include(fileEnv)
+	return nil
+}
+
+// evalImportStatement binds the imported module to its alias, the alias is private
+// to the importing file.
+func evalImportStatement(node *ast.ImportStatement, env *object.Environment) object.Object {
+	if node.Program == nil {
+		return newError("import not loaded: %s", node.Path)
+	}
+
+	moduleEnv, err := evalFile(node.Program, env.Run())
+	if err != nil {
+		return err
+	}
+
+	env.SetPrivate(node.Alias.Value, &object.Module{Name: node.Alias.Value, Env: moduleEnv})
+	return nil
+}
+
+func evalStructStatement(node *ast.StructStatement, env *object.Environment) object.Object {
+	definition := &object.StructDefinition{
+		Name:    node.Name.Value,
+		Fields:  node.Fields,
+		Methods: map[string]*object.Function{},
+		Access:  map[string]string{},
+	}
+
+	for _, field := range node.Fields {
+		if field.Access != "" {
+			definition.Access[field.Name.Value] = field.Access
+		}
+		if !field.Embedded {
+			continue
+		}
+		embedded, ok := env.Get(field.Type.Value)
+		if !ok {
+			return newError("cannot embed %s in struct %s: identifier not found", field.Type.Value, definition.Name)
+		}
+		embeddedDefinition, ok := embedded.(*object.StructDefinition)
+		if !ok {
+			return newError("cannot embed %s in struct %s: %s is %s, not a struct",
+				field.Type.Value, definition.Name, field.Type.Value, embedded.Type())
+		}
+		definition.Embedded = append(definition.Embedded, embeddedDefinition)
+	}
+
+	declare(env, node.Access, node.Name.Value, definition)
+	return nil
+}
+
+func evalMethodStatement(node *ast.FunctionStatement, env *object.Environment) object.Object {
+	receiver := evalIdentifier(node.Receiver, env)
+	if isError(receiver) {
+		return receiver
+	}
+
+	definition, ok := receiver.(*object.StructDefinition)
+	if !ok {
+		return newError("methods can only be declared on structs, %s is %s", node.Receiver.Value, receiver.Type())
+	}
+
+	if definition.HasField(node.Name.Value) {
+		return newError("struct %s has both a field and a method named %s", definition.Name, node.Name.Value)
+	}
+
+	method := &object.Function{
+		Name:       node.Name,
+		Parameters: node.Parameters,
+		ReturnType: node.ReturnType,
+		Block:      node.Block,
+		Env:        env,
+	}
+	definition.Methods[node.Name.Value] = method
+	if node.Access != "" {
+		definition.Access[node.Name.Value] = node.Access
+	}
+	return method
+}
+
+func evalStructLiteral(node *ast.StructLiteral, env *object.Environment) object.Object {
+	var name object.Object
+	if node.Module != nil {
+		module := evalIdentifier(node.Module, env)
+		if isError(module) {
+			return module
+		}
+		name = evalMemberExpression(module, node.Name.Value, env)
+	} else {
+		name = evalIdentifier(node.Name, env)
+	}
+	if isError(name) {
+		return name
+	}
+
+	definition, ok := name.(*object.StructDefinition)
+	if !ok {
+		return newKindError(object.TYPE_ERROR, "not a struct: %s", node.Name.Value)
+	}
+
+	instance := newStruct(definition)
+	for i, field := range node.Fields {
+		if !definition.HasField(field.Value) {
+			return newKindError(object.NAME_ERROR, "unknown field %s in struct %s", field.Value, definition.Name)
+		}
+		if err := checkMemberAccess(definition, field.Value, env); err != nil {
+			return err
+		}
+		value := Eval(node.Values[i], env)
+		if isError(value) {
+			return value
+		}
+		instance.Fields[field.Value] = value
+	}
+
+	return instance
+}
+
+// newStruct creates an instance of the struct with every field set to its zero value,
+// embedded structs are created the same way.
+func newStruct(definition *object.StructDefinition) *object.Struct {
+	instance := &object.Struct{Definition: definition, Fields: map[string]object.Object{}}
+	for _, field := range definition.Fields {
+		instance.Fields[field.Name.Value] = zeroValue(field.Type)
+		if !field.Embedded {
+			continue
+		}
+		for _, embedded := range definition.Embedded {
+			if embedded.Name == field.Type.Value {
+				instance.Fields[field.Name.Value] = newStruct(embedded)
+			}
+		}
+	}
+	return instance
+}
+
+// zeroValue returns the value of a struct field that was not set in its literal
+func zeroValue(t *ast.TypeAnnotation) object.Object {
+	switch t.Value {
+	case "int":
+		return &object.Integer{Value: 0}
+	case "float":
+		return &object.Float{Value: 0}
+	case "string":
+		return &object.String{Value: ""}
+	case "bool":
+		return FALSE
+	default:
+		return NULL
+	}
+}
+
+func evalMemberExpression(obj object.Object, name string, env *object.Environment) object.Object {
+	switch obj := obj.(type) {
+
+	case *object.Struct:
+		owner := findMember(obj, name)
+		if owner == nil {
+			return newKindError(object.NAME_ERROR, "unknown field or method %s in struct %s", name, obj.Definition.Name)
+		}
+		if err := checkMemberAccess(owner.Definition, name, env); err != nil {
+			return err
+		}
+		if value, ok := owner.Fields[name]; ok {
+			return value
+		}
+		return &object.BoundMethod{Receiver: owner, Method: owner.Definition.Methods[name]}
+
+	case *object.Module:
+		return evalModuleMember(obj, name)
+
+	default:
+		return newKindError(object.TYPE_ERROR, "member access not supported: %s", obj.Type())
+	}
+}
+
+// evalModuleMember returns a public declaration of an imported module.
+func evalModuleMember(module *object.Module, name string) object.Object {
+	value, ok := module.Env.Get(name)
+	if !ok {
+		return newKindError(object.NAME_ERROR, "unknown member %s in module %s", name, module.Name)
+	}
+	if module.Env.IsPrivate(name) {
+		return newError("cannot access private member %s of module %s", name, module.Name)
+	}
+	return value
+}
+
+// findMember returns the struct that declares the field or method name, either s itself
+// or one of the structs embedded in it. It returns nil if no struct declares it.
+func findMember(s *object.Struct, name string) *object.Struct {
+	if s.Definition.HasField(name) {
+		return s
+	}
+	if _, ok := s.Definition.Methods[name]; ok {
+		return s
+	}
+	for _, field := range s.Definition.Fields {
+		if !field.Embedded {
+			continue
+		}
+		if embedded, ok := s.Fields[field.Name.Value].(*object.Struct); ok {
+			if owner := findMember(embedded, name); owner != nil {
+				return owner
+			}
+		}
+	}
+	return nil
+}
+
+// checkMemberAccess reports an error when a private member is used outside the methods
+// of its struct, or a protected one outside the methods of its struct and the structs embedding it.
+func checkMemberAccess(definition *object.StructDefinition, name string, env *object.Environment) *object.Error {
+	access := definition.Access[name]
+	if access != "private" && access != "protected" {
+		return nil
+	}
+
+	//the receiver of the method being run, a variable named self does not grant access
+	if caller := env.Receiver(); caller != nil {
+		if caller.Definition == definition {
+			return nil
+		}
+		if access == "protected" && caller.Definition.Embeds(definition) {
+			return nil
+		}
+	}
+	return newError("cannot access %s member %s of struct %s", access, name, definition.Name)
+}
+
+// declare binds the name of a declaration, private names are hidden from the files including it.
+func declare(env *object.Environment, access string, name string, value object.Object) {
+	if access == "private" {
+		env.SetPrivate(name, value)
+		return
+	}
+	env.Set(name, value)
+}
+
+// errorDefinition is the struct of the errors a catch binds, e.g.: Error{message: "bad input", type: "Error"}
+var errorDefinition = &object.StructDefinition{
+	Name: "Error",
+	Fields: []*ast.StructField{
+		{Name: &ast.Identifier{Value: "message"}, Type: &ast.TypeAnnotation{Value: "string"}},
+		{Name: &ast.Identifier{Value: "type"}, Type: &ast.TypeAnnotation{Value: "string"}},
+	},
+	Methods: map[string]*object.Function{},
+	Access:  map[string]string{},
+}
+
+// errorValue returns the value a catch binds for err.
+func errorValue(err *object.Error) *object.Struct {
+	kind := err.Kind
+	if kind == "" {
+		kind = object.RUNTIME_ERROR
+	}
+	return &object.Struct{Definition: errorDefinition, Fields: map[string]object.Object{
+		"message": &object.String{Value: err.Message},
+		"type":    &object.String{Value: kind},
+	}, Caught: err}
+}
+
+// evalThrowStatement throws a message or rethrows a caught error.
+func evalThrowStatement(node *ast.ThrowStatement, env *object.Environment) object.Object {
+	value := Eval(node.Value, env)
+	if isError(value) {
+		return value
+	}
+
+	switch value := value.(type) {
+	case *object.String:
+		return newKindError(object.THROWN_ERROR, "%s", value.Value)
+	case *object.Struct:
+		//an Error value only comes from a catch, it is rethrown as it was caught
+		if value.Definition == errorDefinition {
+			return value.Caught
+		}
+	}
+	return newKindError(object.TYPE_ERROR, "throw expects a STRING or an Error, got %s", typeName(value))
+}
+
+// evalTryStatement runs the catch block when the try block fails, and the finally block
+// in any case. A return, break, continue or error of the finally block replaces the
+// one of the other blocks.
+func evalTryStatement(node *ast.TryStatement, env *object.Environment) object.Object {
+	result := Eval(node.Block, env)
+	if err, ok := result.(*object.Error); ok && node.CatchBlock != nil {
+		catchEnv := object.NewEnclosedEnvironment(env)
+		if node.CatchName != nil {
+			catchEnv.Set(node.CatchName.Value, errorValue(err))
+		}
+		result = Eval(node.CatchBlock, catchEnv)
+	}
+
+	if node.FinallyBlock != nil {
+		if finally := Eval(node.FinallyBlock, env); interruptsBlock(finally) {
+			return finally
+		}
+	}
+	if interruptsBlock(result) {
+		return result
+	}
+	return nil
+}
+
+// interruptsBlock reports whether obj stops the block it comes from: a return value, an error, a break or a continue.
+func interruptsBlock(obj object.Object) bool {
+	if obj == nil {
+		return false
+	}
+	rt := obj.Type()
+	return rt == object.RETURN_VAL_OBJ || rt == object.ERROR_OBJ || rt == object.BREAK_OBJ || rt == object.CONTINUE_OBJ
+}
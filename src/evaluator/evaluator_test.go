@@ -2,18 +2,37 @@ package evaluator
 
 import (
 	"Goslang/lexer"
+	"Goslang/loader"
 	"Goslang/object"
 	"Goslang/parser"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
+// testEval evaluates input as a program. A program evaluates to a PrintObject holding the
+// value of each statement, the way main.go prints them, so the value of the first top-level
+// return, or else of the last statement, is returned for the tests to check.
 func testEval(input string) object.Object {
 	l := lexer.New(input)
 	p := parser.New(l)
 	program := p.ParseProgram()
 	env := object.NewEnvironment()
 
-	return Eval(program, env)
+	evaluated := Eval(program, env)
+	printed, ok := evaluated.(*object.PrintObject)
+	if !ok {
+		return evaluated
+	}
+	if len(printed.Elements) == 0 {
+		return NULL
+	}
+	for _, element := range printed.Elements {
+		if returned, ok := element.(*object.ReturnVal); ok {
+			return returned.Value
+		}
+	}
+	return printed.Elements[len(printed.Elements)-1]
 }
 
 func TestIntegerExpression(t *testing.T) {
@@ -70,6 +89,43 @@ func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
 	return true
 }
 
+func TestFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"3.14", 3.14},
+		{"-2.5", -2.5},
+		{"1.5 + 1.5", 3.0},
+		{"1 + 0.5", 1.5},
+		{"0.5 * 4", 2.0},
+		{"7 / 2.0", 3.5},
+		{"2.0 ^ 3", 8.0},
+		{"4 ^ 0.5", 2.0},
+		{"5.5 % 2", 1.5},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testFloatObject(t, evaluated, tt.expected)
+	}
+}
+
+func testFloatObject(t *testing.T, obj object.Object, expected float64) bool {
+	result, ok := obj.(*object.Float)
+	if !ok {
+		t.Errorf("error: this object is not a float. got=%T (+%v)", obj, obj)
+		return false
+	}
+
+	if result.Value != expected {
+		t.Errorf("error: object has wrong value. got=%f , want=%f .", result.Value, expected)
+		return false
+	}
+
+	return true
+}
+
 func TestEvalBooleanExpression(t *testing.T) {
 
 	tests := []struct {
@@ -95,6 +151,30 @@ func TestEvalBooleanExpression(t *testing.T) {
 		{"(1 < 2) == lie", false},
 		{"(1 > 2) == lie", true},
 		{"(1 > 2) == truth", false},
+		{"1.5 < 2", true},
+		{"2 > 2.5", false},
+		{"2 == 2.0", true},
+		{"0.1 != 0.1", false},
+		{"1 <= 2", true},
+		{"2 <= 2", true},
+		{"3 <= 2", false},
+		{"1 >= 2", false},
+		{"2 >= 2", true},
+		{"2.5 >= 2", true},
+		{"2 <= 1.5", false},
+		{`"a" < "b"`, true},
+		{`"b" <= "a"`, false},
+		{`"abc" >= "abc"`, true},
+		{`"abc" == "abc"`, true},
+		{`"abc" != "abd"`, true},
+		{"truth && truth", true},
+		{"truth && lie", false},
+		{"lie || truth", true},
+		{"lie || lie", false},
+		{"1 && 0", true},
+		{"var x = 5; x >= 0 && x <= 10", true},
+		{"var x = 15; x >= 0 && x <= 10", false},
+		{"var x = -1; x < 0 || x > 10", true},
 	}
 
 	for _, tt := range tests {
@@ -237,6 +317,22 @@ func TestErrorHandling(t *testing.T) {
 			`"Hello" - "World"`,
 			"unknown operator: STRING - STRING",
 		},
+		{
+			"1.5 & 2",
+			"unknown operator: FLOAT & INTEGER",
+		},
+		{
+			"5 / 0",
+			"division by zero",
+		},
+		{
+			"var n = 0; 5 % n",
+			"division by zero",
+		},
+		{
+			"var x = 5; x /= 0;",
+			"division by zero",
+		},
 	}
 
 	for _, tt := range tests {
@@ -283,7 +379,9 @@ func TestFunctionApplication(t *testing.T) {
 		{"fn add(x:int, y:int):int { return x + y; } add(5 + 5, add(5, 5));", 20},
 		{"fn add(x:int, y:int) { return x + y; }; add(5, 5);", 10},
 		{"fn add(x:int, y:int) { return x + y; }; add(5 + 5, add(5, 5));", 20},
-		//{"fn(x:int) { return x; }(5)", 5},
+		{"fn(x:int) { return x; }(5)", 5},
+		{"var add = fn(x:int, y:int):int { return x + y; }; add(2, 3);", 5},
+		{"fn apply(f:fn, x:int) { return f(x); } apply(fn(x:int) { return x * 3; }, 4);", 12},
 	}
 	for _, tt := range tests {
 		testIntegerObject(t, testEval(tt.input), tt.expected)
@@ -300,6 +398,53 @@ addTwo(2);`
 	testIntegerObject(t, testEval(input), 4)
 }
 
+func TestClosuresCaptureTheirEnvironment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{`
+var newCounter = fn() {
+	var count = 0;
+	return fn() { count += 1; return count; };
+};
+var counter = newCounter();
+counter();
+counter();
+counter();`, 3},
+		{`
+var newCounter = fn() {
+	var count = 0;
+	return fn() { count += 1; return count; };
+};
+var first = newCounter();
+var second = newCounter();
+first();
+first();
+second();`, 1},
+		{`
+fn compose(f:fn, g:fn) {
+	return fn(x:int) { return f(g(x)); };
+}
+var inc = fn(x:int) { return x + 1; };
+var double = fn(x:int) { return x * 2; };
+compose(inc, double)(5);`, 11},
+		{`
+fn map(arr:array, f:fn) {
+	var out = [];
+	foreach (x in arr) { push(out, f(x)); }
+	return out;
+}
+var total = 0;
+foreach (x in map([1, 2, 3], fn(x:int) { return x * x; })) { total += x; }
+total;`, 14},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
 func TestStringLiteral(t *testing.T) {
 	input := `"Hello World!"`
 	evaluated := testEval(input)
@@ -324,6 +469,62 @@ func TestStringConcatenation(t *testing.T) {
 	}
 }
 
+func TestStringInterpolation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`var name = "Ann"; var count = 2; "Hello ${name}, you have ${count + 1} items"`, "Hello Ann, you have 3 items"},
+		{`"${1.5} ${truth} ${[1, "a"]} ${"in${"ner"}"}"`, "1.5 truth [1, a] inner"},
+		{`fn f(x:int) { return x * 2; } "${f(2)}${f(3)}"`, "46"},
+		{`"\${x}"`, "${x}"},
+		{`fn f() { var a = 1; } "${f()}!"`, "null!"},
+		{`"${missing}"`, errorMessage("identifier not found: missing")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok {
+				t.Errorf("object is not String. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if str.Value != expected {
+				t.Errorf("String has wrong value. expected=%q, got=%q", expected, str.Value)
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestUnicodeStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`first("Ωμέγα")`, "Ω"},
+		{`"καλημέρα"[1]`, "α"},
+		{`var s = "αβγ"; s[2]`, "γ"},
+		{`"αβγ"[3]`, nil},
+		{`"αβγ"[-1]`, nil},
+		{`chr(945) + chr(65)`, "αA"},
+		{`chr(ord("β"))`, "β"},
+		{`var out = ""; foreach (c in chars("αβγ")) { out = c + out; } out`, "γβα"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if expected, ok := tt.expected.(string); ok {
+			testStringObject(t, evaluated, expected)
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
 func TestBuiltinFunctions(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -334,6 +535,11 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`len("hello world")`, 11},
 		{`len(1)`, "argument to `len` not supported, got INTEGER"},
 		{`len("one", "two")`, "Compile Error: len() function can only have 1 argument"},
+		{`len("Καλημέρα")`, 8},
+		{`ord("α")`, 945},
+		{`ord("ab")`, "argument to `ord` must be a single character, got \"ab\""},
+		{`chr(-1)`, "argument to `chr` is not a unicode code point: -1"},
+		{`chars(5)`, "argument to `chars` must be STRING, got INTEGER"},
 	}
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
@@ -389,7 +595,7 @@ func TestArrayIndexExpressions(t *testing.T) {
 			3,
 		},
 		{
-			"let i = 0; [1][i];",
+			"var i = 0; [1][i];",
 			1,
 		},
 		{
@@ -397,15 +603,15 @@ func TestArrayIndexExpressions(t *testing.T) {
 			3,
 		},
 		{
-			"let myArray = [1, 2, 3]; myArray[2];",
+			"var myArray = [1, 2, 3]; myArray[2];",
 			3,
 		},
 		{
-			"let myArray = [1, 2, 3]; myArray[0] + myArray[1] + myArray[2];",
+			"var myArray = [1, 2, 3]; myArray[0] + myArray[1] + myArray[2];",
 			6,
 		},
 		{
-			"let myArray = [1, 2, 3]; let i = myArray[0]; myArray[i]",
+			"var myArray = [1, 2, 3]; var i = myArray[0]; myArray[i]",
 			2,
 		},
 		{
@@ -427,3 +633,986 @@ func TestArrayIndexExpressions(t *testing.T) {
 		}
 	}
 }
+
+func TestHashLiterals(t *testing.T) {
+	input := `var two = "two";
+	{
+		"one": 10 - 9,
+		two: 1 + 1,
+		"thr" + "ee": 6 / 2,
+		4: 4,
+		truth: 5,
+		lie: 6
+	}`
+	evaluated := testEval(input)
+	result, ok := evaluated.(*object.Hash)
+	if !ok {
+		t.Fatalf("Eval didn't return Hash. got=%T (%+v)", evaluated, evaluated)
+	}
+	expected := map[object.HashKey]int64{
+		(&object.String{Value: "one"}).HashKey():   1,
+		(&object.String{Value: "two"}).HashKey():   2,
+		(&object.String{Value: "three"}).HashKey(): 3,
+		(&object.Integer{Value: 4}).HashKey():      4,
+		TRUE.HashKey():                             5,
+		FALSE.HashKey():                            6,
+	}
+	if len(result.Pairs) != len(expected) {
+		t.Fatalf("Hash has wrong num of pairs. got=%d", len(result.Pairs))
+	}
+	for expectedKey, expectedValue := range expected {
+		pair, ok := result.Pairs[expectedKey]
+		if !ok {
+			t.Errorf("no pair for given key in Pairs")
+		}
+		testIntegerObject(t, pair.Value, expectedValue)
+	}
+	if result.Inspect() != "{one: 1, two: 2, three: 3, 4: 4, truth: 5, lie: 6}" {
+		t.Errorf("hash pairs are not in insertion order. got=%s", result.Inspect())
+	}
+}
+
+func TestHashIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`{"foo": 5}["foo"]`, 5},
+		{`{"foo": 5}["bar"]`, nil},
+		{`var key = "foo"; {"foo": 5}[key]`, 5},
+		{`{}["foo"]`, nil},
+		{`{5: 5}[5]`, 5},
+		{`{truth: 5}[truth]`, 5},
+		{`{lie: 5}[lie]`, 5},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestHashBuiltinFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`len({"a": 1, "b": 2})`, 2},
+		{`keys({"a": 1, 2: "b"})`, `["a", 2]`},
+		{`values({"a": 1, 2: "b"})`, `[1, "b"]`},
+		{`has({"a": 1}, "a")`, true},
+		{`has({"a": 1}, "b")`, false},
+		{`var h = {"a": 1, "b": 2}; delete(h, "a"); keys(h)`, `["b"]`},
+		{`var h = {"a": 1}; delete(h, "b"); len(h)`, 1},
+		{`{"a": 1}[[1]]`, "unusable as hash key: ARRAY"},
+		{`{[1]: 1}`, "unusable as hash key: ARRAY"},
+		{`keys([1])`, "argument to `keys` must be HASH, got ARRAY"},
+		{`has({}, [1])`, "unusable as hash key: ARRAY"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			if errObj, ok := evaluated.(*object.Error); ok {
+				if errObj.Message != expected {
+					t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+				}
+				continue
+			}
+			array, ok := evaluated.(*object.Array)
+			if !ok {
+				t.Errorf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			testArrayElements(t, array, expected)
+		}
+	}
+}
+
+// testArrayElements compares the array against an array literal of strings and integers
+func testArrayElements(t *testing.T, array *object.Array, expected string) bool {
+	want, ok := testEval(expected).(*object.Array)
+	if !ok {
+		t.Errorf("expected %s is not an array literal", expected)
+		return false
+	}
+	if len(array.Elements) != len(want.Elements) {
+		t.Errorf("array has wrong num of elements. want=%d, got=%d", len(want.Elements), len(array.Elements))
+		return false
+	}
+	for i, el := range array.Elements {
+		if el.Type() != want.Elements[i].Type() || el.Inspect() != want.Elements[i].Inspect() {
+			t.Errorf("array element %d is wrong. want=%s, got=%s", i, want.Elements[i].Inspect(), el.Inspect())
+			return false
+		}
+	}
+	return true
+}
+
+func TestWhileLoops(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"var a = []; while (len(a) < 5) { push(a, 1); } len(a);", 5},
+		{"var a = []; while (lie) { push(a, 1); } len(a);", 0},
+		{"var a = []; while (truth) { push(a, 1); if (len(a) == 3) { break; } } len(a);", 3},
+		{`var a = []; var b = [];
+		while (len(a) < 6) {
+			push(a, 1);
+			if (len(a) % 2 == 0) { continue; }
+			push(b, 1);
+		}
+		len(b);`, 3},
+		{"fn f() { var a = []; while (truth) { push(a, 1); if (len(a) == 4) { return len(a); } } } f();", 4},
+		{"var a = []; while (len(a) < 100000) { push(a, 1); } len(a);", 100000},
+		{"while (x) { }", "identifier not found: x"},
+		{"break;", "break outside of a loop"},
+		{"fn f() { continue; } var a = []; while (len(a) < 1) { push(a, 1); f(); }", "continue outside of a loop"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestDoWhileLoops(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"var a = []; do { push(a, 1); } while (len(a) < 5); len(a);", 5},
+		{"var a = []; do { push(a, 1); } while (lie); len(a);", 1},
+		{"var a = []; do { push(a, 1); break; } while (truth); len(a);", 1},
+		{`var a = []; var b = [];
+		do {
+			push(a, 1);
+			if (len(a) > 2) { continue; }
+			push(b, 1);
+		} while (len(a) < 5);
+		len(b);`, 2},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestForLoops(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"var b = []; for (var a = []; len(a) < 5; push(a, 1)) { push(b, len(a)); } len(b);", 5},
+		{"var b = []; for (var a = [1]; lie; push(a, 1)) { push(b, 1); } len(b);", 0},
+		{"var a = []; for (;;) { push(a, 1); if (len(a) == 3) { break; } } len(a);", 3},
+		{`var a = []; var b = [];
+		for (; len(a) < 6; push(a, 1)) {
+			if (len(a) % 2 == 0) { continue; }
+			push(b, 1);
+		}
+		len(b);`, 3},
+		{"var i = 5; for (var i = 0; lie; ) { } i;", 5},
+		{"var sum = 0; for (var i = 0; i < 5; i += 1) { sum += i; } sum;", 10},
+		{"var sum = 0; for (var i = 10; i > 0; i--) { sum++; } sum;", 10},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestForeachLoops(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"var sum = []; foreach (x in [1, 2, 3]) { push(sum, x * 2); } sum;", "[2, 4, 6]"},
+		{`var chars = []; foreach (c in "abc") { push(chars, c); } chars;`, `["a", "b", "c"]`},
+		{`var ks = []; foreach (k in {"a": 1, "b": 2}) { push(ks, k); } ks;`, `["a", "b"]`},
+		{"var out = []; foreach (x in [1, 2, 3, 4]) { if (x == 2) { continue; } if (x == 4) { break; } push(out, x); } out;", "[1, 3]"},
+		{"var a = [1, 2]; foreach (x in a) { push(a, x); } a;", "[1, 2, 1, 2]"},
+		{"var x = 10; foreach (x in [1, 2]) { } x;", 10},
+		{"foreach (x in 5) { }", "foreach not supported: INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			if errObj, ok := evaluated.(*object.Error); ok {
+				if errObj.Message != expected {
+					t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+				}
+				continue
+			}
+			array, ok := evaluated.(*object.Array)
+			if !ok {
+				t.Errorf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			testArrayElements(t, array, expected)
+		}
+	}
+}
+
+func TestAssignExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"var x = 5; x = 10; x;", 10},
+		{"var x = 5; x += 10; x;", 15},
+		{"var x = 5; x -= 10; x;", -5},
+		{"var x = 5; x *= 10; x;", 50},
+		{"var x = 50; x /= 10; x;", 5},
+		{"var x = 17; x %= 5; x;", 2},
+		{"var x = 1; x += 0.5; x;", 1.5},
+		{`var s = "a"; s += "b"; s;`, "ab"},
+		{"var x = 1; var y = 2; x = y = 3; x + y;", 6},
+		{"var x = 1; (x = 5) + 1;", 6},
+		{"var x = 1; fn set() { x = 2; } set(); x;", 2},
+		{"var x = 1; if (truth) { x = 3; } x;", 3},
+		{"var x = 1; foreach (i in [1, 2, 3]) { x += i; } x;", 7},
+		{"var x = 5; var y = x++; y;", 5},
+		{"var x = 5; x++; x;", 6},
+		{"var x = 5; var y = ++x; y;", 6},
+		{"var x = 5; x--; x;", 4},
+		{"var x = 5; var y = --x; y + x;", 8},
+		{"var x = 1.5; x++; x;", 2.5},
+		{"var a = [1, 2, 3]; a[1] = 5; a[1];", 5},
+		{"var a = [1, 2, 3]; a[2] += 5; a[2];", 8},
+		{"var a = [1, 2, 3]; a[0]++; a[0];", 2},
+		{`var h = {"a": 1}; h["a"] = 2; h["b"] = 3; h["a"] + h["b"];`, 5},
+		{`var h = {"a": 1}; h["a"] *= 4; h["a"];`, 4},
+		{"y = 5;", errorMessage("cannot assign to undeclared variable: y")},
+		{"y += 5;", errorMessage("cannot assign to undeclared variable: y")},
+		{"len = 5;", errorMessage("cannot assign to undeclared variable: len")},
+		{"fn f() { var z = 1; } f(); z = 2;", errorMessage("cannot assign to undeclared variable: z")},
+		{"var a = [1]; a[1] = 5;", errorMessage("index out of range: 1")},
+		{`var s = "abc"; s[0] = "x";`, errorMessage("index assignment not supported: STRING")},
+		{`var s = "abc"; s++;`, errorMessage("unknown operator: STRING++")},
+		{"var x = truth; x += 1;", errorMessage("type mismatch: BOOLEAN + INTEGER")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok {
+				t.Errorf("object is not String. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if str.Value != expected {
+				t.Errorf("String has wrong value. got=%q", str.Value)
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+// errorMessage marks the expected result of a test case as an error
+type errorMessage string
+
+func testErrorObject(t *testing.T, obj object.Object, expected string) bool {
+	errObj, ok := obj.(*object.Error)
+	if !ok {
+		t.Errorf("object is not Error. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if errObj.Message != expected {
+		t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+		return false
+	}
+	return true
+}
+
+func testStringObject(t *testing.T, obj object.Object, expected string) bool {
+	str, ok := obj.(*object.String)
+	if !ok {
+		t.Errorf("object is not String. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if str.Value != expected {
+		t.Errorf("String has wrong value. expected=%q, got=%q", expected, str.Value)
+		return false
+	}
+	return true
+}
+
+func TestAssignmentsAreNotPrinted(t *testing.T) {
+	input := "var x = 1; x = 2; x += 1; x++; x;"
+	evaluated := Eval(parser.New(lexer.New(input)).ParseProgram(), object.NewEnvironment())
+	printed, ok := evaluated.(*object.PrintObject)
+	if !ok {
+		t.Fatalf("object is not PrintObject. got=%T (%+v)", evaluated, evaluated)
+	}
+	if len(printed.Elements) != 1 {
+		t.Fatalf("wrong number of printed objects. want=1, got=%d", len(printed.Elements))
+	}
+	testIntegerObject(t, printed.Elements[0], 4)
+}
+
+func TestLogicalOperatorsShortCircuit(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"var a = []; lie && push(a, 1); len(a);", 0},
+		{"var a = []; truth || push(a, 1); len(a);", 0},
+		{"var a = []; truth && push(a, 1); len(a);", 1},
+		{"var a = []; lie || push(a, 1); len(a);", 1},
+		{"var a = []; if (len(a) > 0 && a[0] > 1) { 1 } else { 2 }", 2},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+
+	testErrorObject(t, testEval("truth && x"), "identifier not found: x")
+	testBooleanObject(t, testEval("lie && x"), false)
+}
+
+func TestStructs(t *testing.T) {
+	definitions := `
+struct Point { x:int, y:int }
+struct Person { name:string, age:int, height:float, admin:bool, tags:array }
+fn Point.sum():int { return self.x + self.y; }
+fn Point.move(dx:int, dy:int) { self.x += dx; self.y += dy; return self; }
+`
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"var p = Point{x: 1, y: 2}; p.x;", 1},
+		{"var p = Point{x: 1, y: 2}; p.y;", 2},
+		{"var p = Point{x: 1}; p.y;", 0},
+		{"var p = Point{x: 1, y: 2}; p.x = 10; p.x + p.y;", 12},
+		{"var p = Point{x: 1, y: 2}; p.y *= 5; p.y;", 10},
+		{"var p = Point{x: 1, y: 2}; p.sum();", 3},
+		{"var p = Point{x: 1, y: 2}; p.move(2, 3); p.sum();", 8},
+		{"var p = Point{x: 1, y: 2}; p.move(1, 1).move(1, 1).x;", 3},
+		{"var p = Point{x: 1, y: 2}; var q = p; q.x = 5; p.x;", 5},
+		{"var f = Point{x: 4, y: 5}.sum; f();", 9},
+		{"var p = Point{x: 1, y: 2}; p;", "Point{x: 1, y: 2}"},
+		{`Person{name: "Ann"};`, `Person{name: Ann, age: 0, height: 0.0, admin: lie, tags: null}`},
+		{"Point{z: 1};", errorMessage("unknown field z in struct Point")},
+		{"var p = Point{}; p.z;", errorMessage("unknown field or method z in struct Point")},
+		{"var p = Point{}; p.z = 1;", errorMessage("unknown field z in struct Point")},
+		{"var x = 5; x.y;", errorMessage("member access not supported: INTEGER")},
+		{"var x = 5; x{y: 1};", errorMessage("not a struct: x")},
+		{"fn Point.x() { return 1; }", errorMessage("struct Point has both a field and a method named x")},
+		{"var x = 5; fn x.f() { return 1; }", errorMessage("methods can only be declared on structs, x is INTEGER")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(definitions + tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			if evaluated == nil || evaluated.Inspect() != expected {
+				t.Errorf("wrong Inspect output. expected=%q, got=%v", expected, evaluated)
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestFunctionTypeChecks(t *testing.T) {
+	definitions := `
+struct Point { x:int, y:int }
+struct Circle { r:int }
+fn add(a:int, b:int):int { return a + b; }
+fn half(x:float):float { return x / 2; }
+fn shout(s:string):string { return s + "!"; }
+fn flip(b:bool):bool { return !b; }
+fn count(a:array, h:hash):int { return len(a) + len(h); }
+fn call(f:fn):int { return f(1); }
+fn getX(p:Point):int { return p.x; }
+fn broken():int { return "one"; }
+fn nothing():string { }
+fn origin():Point { return Circle{}; }
+fn wrongType(p:Missing) { return 1; }
+var notAType = 5;
+fn weird(p:notAType) { return 1; }
+`
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"add(1, 2);", 3},
+		{"half(3);", 1.5},
+		{`shout("hi");`, "hi!"},
+		{"count([1, 2], {1: 2});", 3},
+		{"call(fn(x:int):int { return x + 1; });", 2},
+		{"call(flip(lie));", errorMessage("argument f of call: expected FUNCTION, got BOOLEAN")},
+		{"getX(Point{x: 4});", 4},
+		{`add("a", "b");`, errorMessage("argument a of add: expected INTEGER, got STRING")},
+		{"add(1, 2.5);", errorMessage("argument b of add: expected INTEGER, got FLOAT")},
+		{"half(\"1\");", errorMessage("argument x of half: expected FLOAT, got STRING")},
+		{"flip(1);", errorMessage("argument b of flip: expected BOOLEAN, got INTEGER")},
+		{"count({}, []);", errorMessage("argument a of count: expected ARRAY, got HASH")},
+		{"getX(Circle{r: 1});", errorMessage("argument p of getX: expected Point, got Circle")},
+		{"getX(5);", errorMessage("argument p of getX: expected Point, got INTEGER")},
+		{"broken();", errorMessage("return value of broken: expected INTEGER, got STRING")},
+		{"nothing();", errorMessage("return value of nothing: expected STRING, got NULL")},
+		{"origin();", errorMessage("return value of origin: expected Point, got Circle")},
+		{"wrongType(1);", errorMessage("argument p of wrongType: unknown type Missing")},
+		{"weird(1);", errorMessage("argument p of weird: notAType is not a type")},
+		{"fn(x:int) { return x; }(lie);", errorMessage("argument x of anonymous function: expected INTEGER, got BOOLEAN")},
+		{"fn Point.scale(k:int):Point { return k; } Point{}.scale(2);", errorMessage("return value of scale: expected Point, got INTEGER")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(definitions + tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case string:
+			testStringObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestInterfaces(t *testing.T) {
+	definitions := `
+interface Shape { area():int, perimeter():int }
+interface Empty {}
+struct Rect { w:int, h:int }
+struct Circle { r:int }
+struct Square { side:int }
+fn Rect.area():int { return self.w * self.h; }
+fn Rect.perimeter():int { return 2 * (self.w + self.h); }
+fn Circle.area():int { return 3 * self.r * self.r; }
+fn Square.area():int { return self.side * self.side; }
+fn Square.perimeter(scale:int):int { return 4 * self.side * scale; }
+fn describe(s:Shape):int { return s.area() + s.perimeter(); }
+fn anything(e:Empty):int { return 1; }
+`
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"describe(Rect{w: 2, h: 3});", 16},
+		{"var shape = fn(s:Shape):int { return s.area(); }; shape(Rect{w: 4, h: 4});", 16},
+		{"anything(5);", 1},
+		{"anything(Circle{r: 1});", 1},
+		{"describe(Circle{r: 1});",
+			errorMessage("argument s of describe: Circle does not implement Shape (missing methods: perimeter():int)")},
+		{"describe(Square{side: 1});",
+			errorMessage("argument s of describe: Square does not implement Shape (missing methods: perimeter():int)")},
+		{"describe(5);",
+			errorMessage("argument s of describe: INTEGER does not implement Shape (missing methods: area():int, perimeter():int)")},
+		{"fn(s:Shape) { return 1; }(Circle{});",
+			errorMessage("argument s of anonymous function: Circle does not implement Shape (missing methods: perimeter():int)")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(definitions + tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestAccessModifiers(t *testing.T) {
+	definitions := `
+struct Animal { protected name:string, private secret:int, legs:int }
+fn Animal.describe():string { return self.name; }
+private fn Animal.hidden():int { return self.secret; }
+protected fn Animal.guarded():int { return self.legs; }
+fn Animal.reveal():int { return self.hidden(); }
+struct Dog { Animal, breed:string }
+fn Dog.rename(name:string) { self.name = name; return self; }
+fn Dog.peek():int { return self.secret; }
+fn Dog.legCount():int { return self.guarded(); }
+`
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"var a = Animal{legs: 4}; a.legs;", 4},
+		{"var a = Animal{}; a.reveal();", 0},
+		{"var d = Dog{breed: \"lab\"}; d.legs = 3; d.Animal.legs;", 3},
+		{"var d = Dog{}; d.rename(\"Rex\").describe();", "Rex"},
+		{"var d = Dog{Animal: Animal{legs: 4}}; d.legCount();", 4},
+		{"var a = Animal{}; a.name;", errorMessage("cannot access protected member name of struct Animal")},
+		{"var a = Animal{}; a.secret = 1;", errorMessage("cannot access private member secret of struct Animal")},
+		{"Animal{secret: 1};", errorMessage("cannot access private member secret of struct Animal")},
+		{"var a = Animal{}; a.hidden();", errorMessage("cannot access private member hidden of struct Animal")},
+		{"var a = Animal{}; a.guarded();", errorMessage("cannot access protected member guarded of struct Animal")},
+		{"var d = Dog{}; d.peek();", errorMessage("cannot access private member secret of struct Animal")},
+		{"var box = [Animal{}]; var self = box[0]; self.secret;", errorMessage("cannot access private member secret of struct Animal")},
+		{"var a = Animal{}; fn Dog.leak() { var self = a; return self.secret; } Dog{}.leak();", errorMessage("cannot access private member secret of struct Animal")},
+		{"struct Cat { Bird }", errorMessage("cannot embed Bird in struct Cat: identifier not found")},
+		{"var x = 1; struct Cat { x }", errorMessage("cannot embed x in struct Cat: x is INTEGER, not a struct")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(definitions + tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestPrivateDeclarationsAreMarked(t *testing.T) {
+	input := `
+private var hidden = 1;
+var shown = 2;
+private fn helper() { return 3; }
+fn api() { return helper(); }
+private struct Internal { x:int }
+`
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	env := object.NewEnvironment()
+	Eval(program, env)
+
+	expected := map[string]bool{
+		"hidden":   true,
+		"shown":    false,
+		"helper":   true,
+		"api":      false,
+		"Internal": true,
+	}
+
+	for name, private := range expected {
+		if _, ok := env.Get(name); !ok {
+			t.Errorf("%s was not declared", name)
+		}
+		if env.IsPrivate(name) != private {
+			t.Errorf("env.IsPrivate(%q) wrong. want=%t, got=%t", name, private, env.IsPrivate(name))
+		}
+	}
+}
+
+func TestIncludeStatements(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"extra/functions.slang": `#add "state" private fn helper(n:int):int { return n; } fn double(n:int):int { return helper(n) * 2; }`,
+		"extra/state.slang":     `var state = {"n": 0};`,
+		"extra/counter.slang":   `var counter = 0; fn bump() { counter += 1; }`,
+		"extra/wrapper.slang":   `#add "counter"`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`#add "extra/functions" double(21);`, 42},
+		{`#add "extra/state" state["n"] = 5; #add "extra/functions" state["n"];`, 5},
+		{`#add "extra/functions" helper(1);`, errorMessage("identifier not found: helper")},
+		{`#add "extra/counter" bump(); bump(); counter;`, 2},
+		{`#add "extra/counter" counter = 5; bump(); counter;`, 6},
+		{`#add "extra/wrapper" bump(); counter;`, 1},
+		{`var counter = 10; #add "extra/counter" bump(); counter;`, 1},
+	}
+
+	for _, tt := range tests {
+		program, err := loader.New().Load(filepath.Join(dir, "main.slang"), tt.input)
+		if err != nil {
+			t.Fatalf("unexpected load error: %s", err)
+		}
+		evaluated := Eval(program, object.NewEnvironment())
+		if printed, ok := evaluated.(*object.PrintObject); ok {
+			evaluated = printed.Elements[len(printed.Elements)-1]
+		}
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+
+	testErrorObject(t, testEval(`#add "extra/functions"`), "include not loaded: extra/functions")
+}
+
+func TestIncludedFilesAreEvaluatedOncePerRun(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "counter.slang"), []byte(`var n = 0; fn next():int { n += 1; return n; }`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	program, err := loader.New().Load(filepath.Join(dir, "main.slang"), `#add "counter" next(); next();`)
+	if err != nil {
+		t.Fatalf("unexpected load error: %s", err)
+	}
+
+	//the same program run twice starts from a new counter each time
+	for run := 0; run < 2; run++ {
+		evaluated := Eval(program, object.NewEnvironment())
+		printed, ok := evaluated.(*object.PrintObject)
+		if !ok {
+			t.Fatalf("object is not PrintObject. got=%T (%+v)", evaluated, evaluated)
+		}
+		testIntegerObject(t, printed.Elements[len(printed.Elements)-1], 2)
+	}
+}
+
+func TestImportStatements(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"math/geometry.slang": `
+private fn square(n:int):int { return n * n; }
+fn area(side:int):int { return square(side); }
+fn len(x:int):int { return x; }
+struct Point { x:int, y:int }
+fn Point.sum():int { return self.x + self.y; }
+var origin = Point{};`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`import "math/geometry" as geo; geo.area(3);`, 9},
+		{`import "math/geometry"; geometry.area(2);`, 4},
+		{`import "math/geometry" as geo; geo.len(7) + len("ab");`, 9},
+		{`import "math/geometry" as geo; fn area() { return 1; } area() + geo.area(2);`, 5},
+		{`import "math/geometry" as geo; geo.Point{x: 1, y: 2}.sum();`, 3},
+		{`import "math/geometry" as geo; geo.origin.x;`, 0},
+		{`import "math/geometry" as geo; geo.square(2);`, errorMessage("cannot access private member square of module geo")},
+		{`import "math/geometry" as geo; geo.volume(2);`, errorMessage("unknown member volume in module geo")},
+		{`import "math/geometry" as geo; area(2);`, errorMessage("identifier not found: area")},
+	}
+
+	for _, tt := range tests {
+		program, err := loader.New().Load(filepath.Join(dir, "main.slang"), tt.input)
+		if err != nil {
+			t.Fatalf("unexpected load error: %s", err)
+		}
+		evaluated := Eval(program, object.NewEnvironment())
+		if printed, ok := evaluated.(*object.PrintObject); ok {
+			evaluated = printed.Elements[len(printed.Elements)-1]
+		}
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+
+	testErrorObject(t, testEval(`import "math/geometry" as geo;`), "import not loaded: math/geometry")
+}
+
+func TestErrorPositions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"var x = 5;\nx + y;", "ERROR:main.slang:2:5: identifier not found: y"},
+		{"var x = 5;\n  5 + \"a\";", "ERROR:main.slang:2:3: type mismatch: INTEGER + STRING"},
+		{"fn f(a:int) {\n\treturn a - lie;\n}\nf(1);", "ERROR:main.slang:2:9: type mismatch: INTEGER - BOOLEAN"},
+		{"var x = 1;\nbreak;", "ERROR:main.slang:2:1: break outside of a loop"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.NewFile("main.slang", tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("parser errors for %q: %v", tt.input, p.Errors())
+		}
+
+		evaluated := Eval(program, object.NewEnvironment())
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestErrorTraceback(t *testing.T) {
+	input := `fn inner(x:int) {
+	return x + missing;
+}
+fn outer(x:int) {
+	return inner(x) * 2;
+}
+var double = fn(x:int) { return outer(x); };
+double(1);`
+
+	p := parser.New(lexer.NewFile("main.slang", input))
+	evaluated := Eval(p.ParseProgram(), object.NewEnvironment())
+	err, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	expected := `Traceback (most recent call last):
+	main.slang:8:1: call to anonymous function
+	main.slang:7:33: call to outer
+	main.slang:5:9: call to inner
+ERROR:main.slang:2:13: identifier not found: missing`
+	if err.Traceback() != expected {
+		t.Errorf("wrong traceback.\nexpected=%s\ngot=%s", expected, err.Traceback())
+	}
+}
+
+func TestCallStackBelongsToTheRun(t *testing.T) {
+	program := parser.New(lexer.New(`fn inner() { return 1 + "a"; } fn outer() { return inner(); } outer();`)).ParseProgram()
+	env := object.NewEnvironment()
+	err, ok := Eval(program, env).(*object.Error)
+	if !ok {
+		t.Fatalf("object is not Error")
+	}
+	if len(err.Stack) != 2 {
+		t.Errorf("wrong number of frames. expected=2, got=%d", len(err.Stack))
+	}
+	if len(env.Run().Stack) != 0 {
+		t.Errorf("call stack not empty after the run. got=%v", env.Run().Stack)
+	}
+}
+
+func TestErrorOutsideFunctionsHasNoStack(t *testing.T) {
+	evaluated := testEval(`fn one():int { return 1; } one() + "a";`)
+	err, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+	}
+	if len(err.Stack) != 0 {
+		t.Errorf("error has a stack of %d frames, expected none", len(err.Stack))
+	}
+	if err.Traceback() != err.Inspect() {
+		t.Errorf("wrong traceback. got=%q", err.Traceback())
+	}
+}
+
+func TestTryCatchFinally(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`var x = 0; try { x = Atoi("a"); } catch (e) { x = -1; } x;`, -1},
+		{`var x = 0; try { x = Atoi("5"); } catch (e) { x = -1; } x;`, 5},
+		{`var t = ""; try { 1 + "a"; } catch (e) { t = e.type; } t;`, "TypeError"},
+		{`var t = ""; try { missing; } catch (e) { t = e.type + ": " + e.message; } t;`, "NameError: identifier not found: missing"},
+		{`var t = ""; try { var a = [1]; a[3] = 1; } catch (e) { t = e.type; } t;`, "IndexError"},
+		{`var t = ""; try { 1 / 0; } catch (e) { t = e.type + ": " + e.message; } t;`, "ValueError: division by zero"},
+		{`var t = ""; try { throw "bad input"; } catch (e) { t = e.type + ": " + e.message; } t;`, "Error: bad input"},
+		{`fn f() { throw "deep"; } var m = ""; try { f(); } catch (e) { m = e.message; } m;`, "deep"},
+		{`var n = 0; try { n = 1; } finally { n += 10; } n;`, 11},
+		{`var n = 0; try { throw "x"; } catch { n = 1; } finally { n += 10; } n;`, 11},
+		{`fn f() { try { return 1; } finally { return 2; } } f();`, 2},
+		{`fn f() { try { throw "x"; } catch (e) { return e.message; } return "none"; } f();`, "x"},
+		{`var n = 0; while (n < 10) { try { n += 1; break; } finally { n += 5; } } n;`, 6},
+		{`try { throw "x"; } finally { 1; }`, errorMessage("x")},
+		{`try { 1; } catch (e) { e; } throw 5;`, errorMessage("throw expects a STRING or an Error, got INTEGER")},
+		{`try { throw "first"; } catch (e) { throw e; }`, errorMessage("first")},
+		{`try { 1 + "a"; } catch (e) { e; } e;`, errorMessage("identifier not found: e")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestRethrownErrorKeepsItsType(t *testing.T) {
+	evaluated := testEval(`try { 1 + "a"; } catch (e) { throw e; }`)
+	err, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+	}
+	if err.Kind != object.TYPE_ERROR || err.Message != "type mismatch: INTEGER + STRING" {
+		t.Errorf("wrong rethrown error. got kind=%s message=%q", err.Kind, err.Message)
+	}
+}
+
+func TestRethrownErrorKeepsItsPositionAndStack(t *testing.T) {
+	input := `fn inner() {
+	return 1 + "a";
+}
+try {
+	inner();
+} catch (e) {
+	throw e;
+}`
+
+	p := parser.New(lexer.NewFile("main.slang", input))
+	evaluated := Eval(p.ParseProgram(), object.NewEnvironment())
+	err, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	expected := `Traceback (most recent call last):
+	main.slang:5:2: call to inner
+ERROR:main.slang:2:9: type mismatch: INTEGER + STRING`
+	if err.Traceback() != expected {
+		t.Errorf("wrong traceback.\nexpected=%s\ngot=%s", expected, err.Traceback())
+	}
+}
+
+func TestArityDefaultsAndVariadic(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`fn f(x:int, y:int) { return x + y; } f(1);`, errorMessage("wrong number of arguments to f: expected 2, got 1")},
+		{`fn f(x:int) { return x; } f(1, 2);`, errorMessage("wrong number of arguments to f: expected 1, got 2")},
+		{`var f = fn() { return 1; }; f(1);`, errorMessage("wrong number of arguments to anonymous function: expected 0, got 1")},
+		{`fn f(x:int, y:int = 10) { return x + y; } f(1);`, 11},
+		{`fn f(x:int, y:int = 10) { return x + y; } f(1, 2);`, 3},
+		{`fn f(x:int, y:int = x * 3) { return y; } f(2);`, 6},
+		{`fn f(x:int, y:int = 10) { return x; } f();`, errorMessage("wrong number of arguments to f: expected 1 to 2, got 0")},
+		{`fn f(x:int = "a") { return x; } f();`, errorMessage("argument x of f: expected INTEGER, got STRING")},
+		{`fn f(x:float = 1) { return x; } f();`, 1.0},
+		{`fn sum(...n:int) { var t = 0; foreach (i in n) { t += i; } return t; } sum();`, 0},
+		{`fn sum(...n:int) { var t = 0; foreach (i in n) { t += i; } return t; } sum(1, 2, 3);`, 6},
+		{`fn f(a:string, ...rest:int) { return len(rest); } f("x", 1, 2);`, 2},
+		{`fn f(a:string, ...rest:int) { return a; } f();`, errorMessage("wrong number of arguments to f: expected at least 1, got 0")},
+		{`fn f(...rest:int) { return rest; } f(1, "b");`, errorMessage("argument rest of f: expected INTEGER, got STRING")},
+		{`struct P { x:int } fn P.add(n:int = 1) { return self.x + n; } P{x: 5}.add();`, 6},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestNamedArguments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`fn f(x:int, y:int) { return x - y; } f(y: 1, x: 5);`, 4},
+		{`fn f(x:int, y:int) { return x - y; } f(5, y: 1);`, 4},
+		{`fn f(x:int, y:int = 10, z:int = 100) { return x + y + z; } f(1, z: 0);`, 11},
+		{`fn f(x:int, y:int = x * 2) { return y; } f(x: 3);`, 6},
+		{`fn f(x:int, y:int) { return x; } f(1, z: 2);`, errorMessage("f has no parameter z")},
+		{`fn f(x:int, y:int) { return x; } f(1, x: 2);`, errorMessage("argument x of f is given more than once")},
+		{`fn f(x:int, y:int) { return x; } f(y: 1, y: 2);`, errorMessage("argument y of f is given more than once")},
+		{`fn f(x:int, y:int) { return x; } f(y: 1);`, errorMessage("wrong number of arguments to f: expected 2, got 1")},
+		{`fn f(x:int, y:int = 1) { return x; } f(y: 1);`, errorMessage("missing argument x of f")},
+		{`fn f(x:int, y:int) { return x; } f(1, y: "a");`, errorMessage("argument y of f: expected INTEGER, got STRING")},
+		{`fn f(a:int, ...n:int) { return a; } f(1, n: 2);`, errorMessage("variadic parameter n of f cannot be given by name")},
+		{`fn f(a:int, ...n:int) { return len(n); } f(a: 1);`, 0},
+		{`len(s: "a");`, errorMessage("builtin functions do not take named arguments")},
+		{`struct P { x:int } fn P.add(n:int, m:int = 0) { return self.x + n - m; } P{x: 5}.add(m: 1, n: 2);`, 6},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestMatchExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`match (2) { 1 => 10, 2 => 20, _ => 30 }`, 20},
+		{`match (7) { 1 => 10, 2 => 20, _ => 30 }`, 30},
+		{`match ("b") { "a" | "b" => 1, _ => 2 }`, 1},
+		{`match (2.0) { 2 => 1, _ => 2 }`, 1},
+		{`match (-3) { -3 => 1, _ => 2 }`, 1},
+		{`match (lie) { truth => 1, lie => 2 }`, 2},
+		{`match ([1, 2]) { [x] => x, [x, y] => x + y }`, 3},
+		{`match ([1, [2, 3]]) { [a, [b, c]] => a * 100 + b * 10 + c }`, 123},
+		{`match ([]) { [] => 1, _ => 2 }`, 1},
+		{`match ("1") { 1 => 1, _ => 2 }`, 2},
+		{`match (5) { n if n > 10 => 1, n if n > 3 => 2, _ => 3 }`, 2},
+		{`match ([4, 2]) { [a, b] if a < b => b, [a, b] => { var d = a - b; d * 10 } }`, 20},
+		{`var n = 1; match (9) { n => n }; n`, 1},
+		{`fn f(x:int) { match (x) { 0 => { return 100; } _ => 1 }; return 2; } f(0)`, 100},
+		{`var total = 0; foreach (i in [1, 2, 3, 4]) { match (i) { 3 => { break; } _ => { total += i; } } } total`, 3},
+		{`match (5) { 1 => 1 }`, errorMessage("no match arm matches 5")},
+		{`match ([1]) { [x] if x + "a" => 1 }`, errorMessage("type mismatch: INTEGER + STRING")},
+		{`var r = match (1) { 1 => { var z = 1; } }; r;`, nil},
+		{`var r = match (1) { 1 => { var z = 1; } }; r + 1;`, errorMessage("type mismatch: NULL + INTEGER")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case nil:
+			testNullObject(t, evaluated)
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
@@ -1,35 +1,75 @@
 package lexer
 
-import "Goslang/token"
+import (
+	"Goslang/token"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
 
 type Lexer struct {
-	input        string //The input
-	position     int    //Current position in input(points to current char)
-	readPosition int    //Current reading position in input (after current char)
-	ch           byte   //Current character under examination
+	input        string          //The input
+	position     int             //Current position in input(points to current char)
+	readPosition int             //Current reading position in input (after current char)
+	ch           byte            //Current character under examination
+	file         string          //The file the input was read from, empty for the REPL and tests
+	line         int             //Line of the current char, starting at 1
+	column       int             //Column of the current char, starting at 1
+	tokenLine    int             //Line the last token ended on, 0 before the first token
+	templates    []int           //Brace depth inside each ${...} being lexed, the innermost last
+	lastType     token.TokenType //Type of the last token, empty before the first token
 }
 
 func New(input string) *Lexer {
+	return NewFile("", input)
+}
+
+// NewFile returns a lexer whose token positions name the file the input was read from.
+func NewFile(file string, input string) *Lexer {
 	l := &Lexer{
 		input: input,
+		file:  file,
+		line:  1,
 	}
 	l.readChar()
 	return l
 }
 
 func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.line++
+		l.column = 0
+	}
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
 	} else {
 		l.ch = l.input[l.readPosition]
 	}
+	//columns count characters, the continuation bytes of a UTF-8 character do not start one
+	if l.ch&0xC0 != 0x80 {
+		l.column++
+	}
 	l.position = l.readPosition
 	l.readPosition = l.readPosition + 1
 }
 func (l *Lexer) NextToken() token.Token {
-	var tok token.Token
+	doc := l.skipTrivia()
+
+	pos := token.Position{File: l.file, Line: l.line, Column: l.column}
+	tok := l.readToken()
+	if !tok.Pos.IsValid() {
+		tok.Pos = pos
+	}
+	tok.Doc = doc
+	l.tokenLine = l.line
+	l.lastType = tok.Type
+	return tok
+}
 
-	l.skipWhitespace()
+// readToken reads the token starting at the current char, whitespace is already skipped.
+func (l *Lexer) readToken() token.Token {
+	var tok token.Token
 
 	switch l.ch {
 
@@ -39,15 +79,29 @@ func (l *Lexer) NextToken() token.Token {
 			l.readChar()
 			literal := string(ch) + string(l.ch)
 			tok = token.Token{Type: token.EQ, Literal: literal}
+		} else if l.peekChar() == '>' {
+			tok = l.makeTwoCharToken(token.ARROW)
 		} else {
 			tok = newToken(token.ASSIGN, l.ch)
 		}
 
 	case '+':
-		tok = newToken(token.PLUS, l.ch)
+		if l.peekChar() == '=' {
+			tok = l.makeTwoCharToken(token.PLUS_ASSIGN)
+		} else if l.peekChar() == '+' {
+			tok = l.makeTwoCharToken(token.INCREMENT)
+		} else {
+			tok = newToken(token.PLUS, l.ch)
+		}
 
 	case '-':
-		tok = newToken(token.MINUS, l.ch)
+		if l.peekChar() == '=' {
+			tok = l.makeTwoCharToken(token.MINUS_ASSIGN)
+		} else if l.peekChar() == '-' {
+			tok = l.makeTwoCharToken(token.DECREMENT)
+		} else {
+			tok = newToken(token.MINUS, l.ch)
+		}
 
 	case '!':
 		if l.peekChar() == '=' {
@@ -60,34 +114,75 @@ func (l *Lexer) NextToken() token.Token {
 		}
 
 	case '/':
-		tok = newToken(token.SLASH, l.ch)
+		if l.peekChar() == '*' {
+			//skipTrivia leaves only the block comments that are never closed
+			for l.ch != 0 {
+				l.readChar()
+			}
+			return token.Token{Type: token.ILLEGAL, Literal: "unterminated block comment"}
+		} else if l.peekChar() == '=' {
+			tok = l.makeTwoCharToken(token.SLASH_ASSIGN)
+		} else {
+			tok = newToken(token.SLASH, l.ch)
+		}
 
 	case '*':
-		tok = newToken(token.ASTERISK, l.ch)
+		if l.peekChar() == '=' {
+			tok = l.makeTwoCharToken(token.ASTERISK_ASSIGN)
+		} else {
+			tok = newToken(token.ASTERISK, l.ch)
+		}
 
 	case '^':
 		tok = newToken(token.POWER, l.ch)
 
 	case '%':
-		tok = newToken(token.MODULUS, l.ch)
+		if l.peekChar() == '=' {
+			tok = l.makeTwoCharToken(token.MODULUS_ASSIGN)
+		} else {
+			tok = newToken(token.MODULUS, l.ch)
+		}
 
 	case '&':
-		tok = newToken(token.BITAND, l.ch)
+		if l.peekChar() == '&' {
+			tok = l.makeTwoCharToken(token.AND)
+		} else {
+			tok = newToken(token.BITAND, l.ch)
+		}
 
 	case '|':
-		tok = newToken(token.BITOR, l.ch)
+		if l.peekChar() == '|' {
+			tok = l.makeTwoCharToken(token.OR)
+		} else {
+			tok = newToken(token.BITOR, l.ch)
+		}
 
 	case '~':
 		tok = newToken(token.BITNOT, l.ch)
 
 	case '#':
-		tok = newToken(token.BITXOR, l.ch)
+		if l.isIncludeDirective() {
+			l.readChar()
+			l.readChar()
+			l.readChar()
+			tok = token.Token{Type: token.INCLUDE, Literal: "#add"}
+		} else {
+			tok = newToken(token.BITXOR, l.ch)
+		}
 
 	case '<':
-		tok = newToken(token.LT, l.ch)
+		if l.peekChar() == '=' {
+			tok = l.makeTwoCharToken(token.LT_EQ)
+		} else {
+			tok = newToken(token.LT, l.ch)
+		}
 
 	case '>':
-		tok = newToken(token.GT, l.ch)
+		if l.peekChar() == '=' {
+			tok = l.makeTwoCharToken(token.GT_EQ)
+		} else {
+			tok = newToken(token.GT, l.ch)
+		}
 
 	case ';':
 		tok = newToken(token.SEMICOLON, l.ch)
@@ -95,9 +190,20 @@ func (l *Lexer) NextToken() token.Token {
 	case ':':
 		tok = newToken(token.COLON, l.ch)
 
+	case '.':
+		if l.peekChar() == '.' && l.peekCharAt(1) == '.' {
+			l.readChar()
+			l.readChar()
+			tok = token.Token{Type: token.ELLIPSIS, Literal: "..."}
+		} else {
+			tok = newToken(token.DOT, l.ch)
+		}
+
 	case '"':
-		tok.Type = token.STRING
-		tok.Literal = l.readString()
+		return l.readString(false)
+
+	case '`':
+		return l.readRawString()
 
 	case 'i':
 		if l.peekChar() == 'n' && !l.isWhiteSpace(l.peekCharAt(1)) {
@@ -143,9 +249,20 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.RPAREN, l.ch)
 
 	case '{':
+		if len(l.templates) > 0 {
+			l.templates[len(l.templates)-1]++
+		}
 		tok = newToken(token.LBRACE, l.ch)
 
 	case '}':
+		if len(l.templates) > 0 {
+			if l.templates[len(l.templates)-1] == 0 {
+				//the } closes the ${...}, the string goes on after it
+				l.templates = l.templates[:len(l.templates)-1]
+				return l.readString(true)
+			}
+			l.templates[len(l.templates)-1]--
+		}
 		tok = newToken(token.RBRACE, l.ch)
 
 	case '[':
@@ -164,11 +281,15 @@ func (l *Lexer) NextToken() token.Token {
 			tok.Type = token.LookupIdent(tok.Literal)
 			return tok
 		} else if isDigit(l.ch) {
-			tok.Type = token.INT
-			tok.Literal = l.readNumber()
+			tok.Literal, tok.Type = l.readNumber()
 			return tok
 		} else {
-			tok = newToken(token.ILLEGAL, l.ch)
+			//a character outside of ASCII takes the bytes of its UTF-8 encoding
+			ch, size := utf8.DecodeRuneInString(l.input[l.position:])
+			for i := 1; i < size; i++ {
+				l.readChar()
+			}
+			tok = token.Token{Type: token.ILLEGAL, Literal: fmt.Sprintf("illegal character '%c'", ch)}
 		}
 	}
 
@@ -180,6 +301,34 @@ func newToken(tokenType token.TokenType, ch byte) token.Token {
 	return token.Token{Type: tokenType, Literal: string(ch)}
 }
 
+// makeTwoCharToken consumes the current and the next character as one token, e.g. "+="
+func (l *Lexer) makeTwoCharToken(tokenType token.TokenType) token.Token {
+	ch := l.ch
+	l.readChar()
+	return token.Token{Type: tokenType, Literal: string(ch) + string(l.ch)}
+}
+
+// isIncludeDirective reports whether the current '#' starts an #add directive: it is written
+// exactly "#add", starts a statement or a line and is followed by a string. Otherwise '#' is
+// the xor operator.
+func (l *Lexer) isIncludeDirective() bool {
+	switch l.lastType {
+	case "", token.SEMICOLON, token.LBRACE, token.RBRACE:
+	default:
+		if l.tokenLine == l.line {
+			return false
+		}
+	}
+	if l.peekCharAt(0) != 'a' || l.peekCharAt(1) != 'd' || l.peekCharAt(2) != 'd' {
+		return false
+	}
+	offset := 3
+	for l.isWhiteSpace(l.peekCharAt(offset)) {
+		offset++
+	}
+	return l.peekCharAt(offset) == '"' || l.peekCharAt(offset) == '`'
+}
+
 func isLetter(ch byte) bool {
 	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_' || ch == '$'
 }
@@ -188,12 +337,22 @@ func isDigit(ch byte) bool {
 	return '0' <= ch && ch <= '9'
 }
 
-func (l *Lexer) readNumber() string {
+// readNumber reads an integer or, when the digits are followed by a '.' and
+// another digit, a floating point number.
+func (l *Lexer) readNumber() (string, token.TokenType) {
 	position := l.position
+	var tokenType token.TokenType = token.INT
 	for isDigit(l.ch) {
 		l.readChar()
 	}
-	return l.input[position:l.position]
+	if l.ch == '.' && isDigit(l.peekChar()) {
+		tokenType = token.FLOAT
+		l.readChar()
+		for isDigit(l.ch) {
+			l.readChar()
+		}
+	}
+	return l.input[position:l.position], tokenType
 }
 
 func (l *Lexer) readIdentifier() string {
@@ -204,15 +363,129 @@ func (l *Lexer) readIdentifier() string {
 	return l.input[position:l.position]
 }
 
-func (l *Lexer) readString() string {
+// readString reads the string literal starting at the current '"' and decodes its escape
+// sequences. A literal that is not closed on its line, or that has an invalid escape
+// sequence, is returned as an ILLEGAL token describing the problem.
+//
+// A ${ in the literal ends it as a TEMPLATE token, the tokens of the embedded expression
+// follow and the } that closes it reads the rest of the literal, continued is then true.
+func (l *Lexer) readString(continued bool) token.Token {
+	var out strings.Builder
+	var invalid *token.Token
+
+	for {
+		l.readChar()
+		switch l.ch {
+		case '"':
+			l.readChar()
+			if invalid != nil {
+				return *invalid
+			}
+			if continued {
+				return stringToken(token.TEMPLATE_END, out.String())
+			}
+			return stringToken(token.STRING, out.String())
+		case '$':
+			if l.peekChar() != '{' {
+				out.WriteByte(l.ch)
+				continue
+			}
+			l.readChar()
+			l.readChar()
+			l.templates = append(l.templates, 0)
+			if invalid != nil {
+				return *invalid
+			}
+			return stringToken(token.TEMPLATE, out.String())
+		case '\n', 0:
+			return token.Token{Type: token.ILLEGAL, Literal: "unterminated string literal"}
+		case '\\':
+			pos := token.Position{File: l.file, Line: l.line, Column: l.column}
+			l.readChar()
+			if l.ch == '\n' || l.ch == 0 {
+				return token.Token{Type: token.ILLEGAL, Literal: "unterminated string literal"}
+			}
+			if msg := l.readEscape(&out); msg != "" && invalid == nil {
+				//the rest of the literal is still read, so that lexing goes on after it
+				invalid = &token.Token{Type: token.ILLEGAL, Literal: msg, Pos: pos}
+			}
+		default:
+			out.WriteByte(l.ch)
+		}
+	}
+}
+
+// stringToken returns the token of a string literal, its text must be valid UTF-8.
+func stringToken(tokenType token.TokenType, literal string) token.Token {
+	if !utf8.ValidString(literal) {
+		return token.Token{Type: token.ILLEGAL, Literal: "invalid UTF-8 encoding in string literal"}
+	}
+	return token.Token{Type: tokenType, Literal: literal}
+}
+
+// escapes maps the characters that follow a '\\' in a string literal to what they stand for.
+var escapes = map[byte]byte{
+	'n':  '\n',
+	't':  '\t',
+	'r':  '\r',
+	'0':  0,
+	'\\': '\\',
+	'"':  '"',
+	'\'': '\'',
+	'$':  '$',
+}
+
+// readEscape decodes the escape sequence whose first character after the '\\' is the
+// current char into out. The current char is left on the last character of the sequence.
+// It returns a description of the sequence when it is invalid.
+func (l *Lexer) readEscape(out *strings.Builder) string {
+	if ch, ok := escapes[l.ch]; ok {
+		out.WriteByte(ch)
+		return ""
+	}
+	if l.ch != 'u' {
+		return fmt.Sprintf("invalid escape sequence \\%c in string literal", l.ch)
+	}
+
+	//\u{...} holds the code point of a unicode character in hex
+	if l.peekChar() != '{' {
+		return "invalid unicode escape, expected \\u{...}"
+	}
+	l.readChar()
+	position := l.position + 1
+	for l.peekChar() != '}' && l.peekChar() != '"' && l.peekChar() != '\n' && l.peekChar() != 0 {
+		l.readChar()
+	}
+	digits := l.input[position:l.readPosition]
+	if l.peekChar() != '}' {
+		return "invalid unicode escape, expected \\u{...}"
+	}
+	l.readChar()
+
+	code, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return fmt.Sprintf("invalid unicode escape \\u{%s}", digits)
+	}
+	out.WriteRune(rune(code))
+	return ""
+}
+
+// readRawString reads the string literal between backticks starting at the current char.
+// It has no escape sequences and can span lines, carriage returns are dropped.
+func (l *Lexer) readRawString() token.Token {
 	position := l.position + 1
 	for {
 		l.readChar()
-		if l.ch == '"' || l.ch == 0 {
+		if l.ch == '`' {
 			break
 		}
+		if l.ch == 0 {
+			return token.Token{Type: token.ILLEGAL, Literal: "unterminated raw string literal"}
+		}
 	}
-	return l.input[position:l.position]
+	literal := strings.ReplaceAll(l.input[position:l.position], "\r", "")
+	l.readChar()
+	return stringToken(token.STRING, literal)
 }
 
 func (l *Lexer) skipWhitespace() {
@@ -220,6 +493,63 @@ func (l *Lexer) skipWhitespace() {
 		l.readChar()
 	}
 }
+
+// skipTrivia skips the whitespace and the comments before the next token and returns
+// the doc comment of the token: the comments on the lines right above it, with no blank
+// line in between. A comment that follows another token on its line is not a doc comment.
+// A block comment that is never closed is left for readToken to report.
+func (l *Lexer) skipTrivia() string {
+	var doc []string
+	docEnd := 0 //the line the doc comment ends on
+
+	for {
+		l.skipWhitespace()
+		if l.ch != '/' || (l.peekChar() != '/' && l.peekChar() != '*') {
+			break
+		}
+		if l.peekChar() == '*' && !strings.Contains(l.input[l.readPosition+1:], "*/") {
+			break
+		}
+
+		start := l.line
+		text := l.readComment()
+		if start == l.tokenLine {
+			doc = nil
+			continue
+		}
+		if start > docEnd+1 {
+			doc = nil
+		}
+		doc = append(doc, text)
+		docEnd = l.line
+	}
+
+	if len(doc) == 0 || l.line > docEnd+1 {
+		return ""
+	}
+	return strings.Join(doc, "\n")
+}
+
+// readComment reads the // or /* */ comment starting at the current char and returns
+// its text without the comment markers.
+func (l *Lexer) readComment() string {
+	if l.peekChar() == '/' {
+		position := l.position + 2
+		for l.ch != '\n' && l.ch != 0 {
+			l.readChar()
+		}
+		return strings.TrimSpace(l.input[position:l.position])
+	}
+
+	position := l.position + 2
+	for !(l.ch == '*' && l.peekChar() == '/') {
+		l.readChar()
+	}
+	text := l.input[position:l.position]
+	l.readChar()
+	l.readChar()
+	return strings.TrimSpace(text)
+}
 func (l *Lexer) isWhiteSpace(ch byte) bool {
 	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
 }
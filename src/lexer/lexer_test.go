@@ -12,7 +12,7 @@ var add = fn(x, y) {
 x + y;
 };
 var result = add(five, ten);
-!-/*5;
+!-/ *5;
 5 < 10 > 5;
 
 if (5 < 10) {
@@ -28,6 +28,18 @@ return lie;
 "foobar"
 "foo bar"
 [1, 2];
+3.14 + 2;
+while do break continue
+for foreach (x in y)
+x += 1 -= *= /= %= ++ --
+<= >= && || & |
+struct p.x
+interface public private protected
+#add "extra/functions"; # add "x"; 10 #add "y"; #address
+import "math/geometry" as geo;
+throw try catch finally
+...rest a.b
+match _ => = >
 `
 
 	tests := []struct {
@@ -117,6 +129,77 @@ return lie;
 		{token.INT, "2"},
 		{token.RBRACKET, "]"},
 		{token.SEMICOLON, ";"},
+		{token.FLOAT, "3.14"},
+		{token.PLUS, "+"},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.WHILE, "while"},
+		{token.DO, "do"},
+		{token.BREAK, "break"},
+		{token.CONTINUE, "continue"},
+		{token.FOR, "for"},
+		{token.FOREACH, "foreach"},
+		{token.LPAREN, "("},
+		{token.IDENT, "x"},
+		{token.IN, "in"},
+		{token.IDENT, "y"},
+		{token.RPAREN, ")"},
+		{token.IDENT, "x"},
+		{token.PLUS_ASSIGN, "+="},
+		{token.INT, "1"},
+		{token.MINUS_ASSIGN, "-="},
+		{token.ASTERISK_ASSIGN, "*="},
+		{token.SLASH_ASSIGN, "/="},
+		{token.MODULUS_ASSIGN, "%="},
+		{token.INCREMENT, "++"},
+		{token.DECREMENT, "--"},
+		{token.LT_EQ, "<="},
+		{token.GT_EQ, ">="},
+		{token.AND, "&&"},
+		{token.OR, "||"},
+		{token.BITAND, "&"},
+		{token.BITOR, "|"},
+		{token.STRUCT, "struct"},
+		{token.IDENT, "p"},
+		{token.DOT, "."},
+		{token.IDENT, "x"},
+		{token.INTERFACE, "interface"},
+		{token.PUBLIC, "public"},
+		{token.PRIVATE, "private"},
+		{token.PROTECTED, "protected"},
+		{token.INCLUDE, "#add"},
+		{token.STRING, "extra/functions"},
+		{token.SEMICOLON, ";"},
+		{token.BITXOR, "#"},
+		{token.IDENT, "add"},
+		{token.STRING, "x"},
+		{token.SEMICOLON, ";"},
+		{token.INT, "10"},
+		{token.BITXOR, "#"},
+		{token.IDENT, "add"},
+		{token.STRING, "y"},
+		{token.SEMICOLON, ";"},
+		{token.BITXOR, "#"},
+		{token.IDENT, "address"},
+		{token.IMPORT, "import"},
+		{token.STRING, "math/geometry"},
+		{token.AS, "as"},
+		{token.IDENT, "geo"},
+		{token.SEMICOLON, ";"},
+		{token.THROW, "throw"},
+		{token.TRY, "try"},
+		{token.CATCH, "catch"},
+		{token.FINALLY, "finally"},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "rest"},
+		{token.IDENT, "a"},
+		{token.DOT, "."},
+		{token.IDENT, "b"},
+		{token.MATCH, "match"},
+		{token.IDENT, "_"},
+		{token.ARROW, "=>"},
+		{token.ASSIGN, "="},
+		{token.GT, ">"},
 		{token.EOF, ""},
 	}
 
@@ -135,3 +218,213 @@ return lie;
 		}
 	}
 }
+
+func TestTokenPositions(t *testing.T) {
+	input := "var x = 5;\n\tx += \"ab\";\n\nif (x) {}"
+
+	tests := []struct {
+		expectedLiteral string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{"var", 1, 1},
+		{"x", 1, 5},
+		{"=", 1, 7},
+		{"5", 1, 9},
+		{";", 1, 10},
+		{"x", 2, 2},
+		{"+=", 2, 4},
+		{"ab", 2, 7},
+		{";", 2, 11},
+		{"if", 4, 1},
+		{"(", 4, 4},
+		{"x", 4, 5},
+		{")", 4, 6},
+		{"{", 4, 8},
+		{"}", 4, 9},
+		{"", 4, 10},
+	}
+
+	l := NewFile("main.slang", input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Pos.File != "main.slang" || tok.Pos.Line != tt.expectedLine || tok.Pos.Column != tt.expectedColumn {
+			t.Errorf("tests[%d] - position of %q wrong. expected=main.slang:%d:%d, got=%s",
+				i, tt.expectedLiteral, tt.expectedLine, tt.expectedColumn, tok.Pos)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `// adds two numbers
+// and returns the sum
+fn add /* inline */ (x) { // trailing
+	x / 2; /* a block
+	comment */ x
+}
+
+/** not the doc of y,
+    a blank line follows */
+
+var y = 1 // after y
+var z /* unterminated`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedLine    int
+		expectedDoc     string
+	}{
+		{token.FUNCTION, "fn", 3, "adds two numbers\nand returns the sum"},
+		{token.IDENT, "add", 3, ""},
+		{token.LPAREN, "(", 3, ""},
+		{token.IDENT, "x", 3, ""},
+		{token.RPAREN, ")", 3, ""},
+		{token.LBRACE, "{", 3, ""},
+		{token.IDENT, "x", 4, ""},
+		{token.SLASH, "/", 4, ""},
+		{token.INT, "2", 4, ""},
+		{token.SEMICOLON, ";", 4, ""},
+		{token.IDENT, "x", 5, ""},
+		{token.RBRACE, "}", 6, ""},
+		{token.VAR, "var", 11, ""},
+		{token.IDENT, "y", 11, ""},
+		{token.ASSIGN, "=", 11, ""},
+		{token.INT, "1", 11, ""},
+		{token.VAR, "var", 12, ""},
+		{token.IDENT, "z", 12, ""},
+		{token.ILLEGAL, "unterminated block comment", 12, ""},
+		{token.EOF, "", 12, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - token wrong. expected=%s %q, got=%s %q", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+		if tok.Pos.Line != tt.expectedLine {
+			t.Errorf("tests[%d] - line of %q wrong. expected=%d, got=%d", i, tt.expectedLiteral, tt.expectedLine, tok.Pos.Line)
+		}
+		if tok.Doc != tt.expectedDoc {
+			t.Errorf("tests[%d] - doc of %q wrong. expected=%q, got=%q", i, tt.expectedLiteral, tt.expectedDoc, tok.Doc)
+		}
+	}
+}
+
+func TestStringLiterals(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedColumn  int
+	}{
+		{`"a \"quoted\" word"`, token.STRING, `a "quoted" word`, 1},
+		{`"tab\there\nnew line\\"`, token.STRING, "tab\there\nnew line\\", 1},
+		{`"\u{3b1}\u{1F600}\u{41}"`, token.STRING, "α😀A", 1},
+		{`"καλημέρα"`, token.STRING, "καλημέρα", 1},
+		{"`raw \\n ${x}\r\nsecond line`", token.STRING, "raw \\n ${x}\nsecond line", 1},
+		{`"no end`, token.ILLEGAL, "unterminated string literal", 1},
+		{"\"line\nbreak\"", token.ILLEGAL, "unterminated string literal", 1},
+		{"`no end", token.ILLEGAL, "unterminated raw string literal", 1},
+		{`"a \q"`, token.ILLEGAL, `invalid escape sequence \q in string literal`, 4},
+		{`"\u{110000}"`, token.ILLEGAL, `invalid unicode escape \u{110000}`, 2},
+		{`"\u41"`, token.ILLEGAL, `invalid unicode escape, expected \u{...}`, 2},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input + ";")
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Errorf("wrong token for %q. expected=%s %q, got=%s %q", tt.input, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+		if tok.Pos.Column != tt.expectedColumn {
+			t.Errorf("wrong column for %q. expected=%d, got=%d", tt.input, tt.expectedColumn, tok.Pos.Column)
+		}
+		if tt.expectedType == token.STRING && l.NextToken().Type != token.SEMICOLON {
+			t.Errorf("the literal %q was not read to its end", tt.input)
+		}
+	}
+}
+
+func TestStringInterpolation(t *testing.T) {
+	input := `"a ${x + "b${y}"} c ${ {1: 2}[1] }" "\${z} $5"`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.TEMPLATE, "a "},
+		{token.IDENT, "x"},
+		{token.PLUS, "+"},
+		{token.TEMPLATE, "b"},
+		{token.IDENT, "y"},
+		{token.TEMPLATE_END, ""},
+		{token.TEMPLATE, " c "},
+		{token.LBRACE, "{"},
+		{token.INT, "1"},
+		{token.COLON, ":"},
+		{token.INT, "2"},
+		{token.RBRACE, "}"},
+		{token.LBRACKET, "["},
+		{token.INT, "1"},
+		{token.RBRACKET, "]"},
+		{token.TEMPLATE_END, ""},
+		{token.STRING, "${z} $5"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - token wrong. expected=%s %q, got=%s %q", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestUnicodeSource(t *testing.T) {
+	input := "var s = \"αβγ\"; s λ"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedColumn  int
+	}{
+		{token.VAR, "var", 1},
+		{token.IDENT, "s", 5},
+		{token.ASSIGN, "=", 7},
+		{token.STRING, "αβγ", 9},
+		{token.SEMICOLON, ";", 14},
+		{token.IDENT, "s", 16},
+		{token.ILLEGAL, "illegal character 'λ'", 18},
+		{token.EOF, "", 19},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - token wrong. expected=%s %q, got=%s %q", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+		if tok.Pos.Column != tt.expectedColumn {
+			t.Errorf("tests[%d] - column of %q wrong. expected=%d, got=%d", i, tt.expectedLiteral, tt.expectedColumn, tok.Pos.Column)
+		}
+	}
+
+	if tok := New("\"\xff\"").NextToken(); tok.Type != token.ILLEGAL {
+		t.Errorf("invalid UTF-8 in a string literal was not reported. got=%s %q", tok.Type, tok.Literal)
+	}
+}
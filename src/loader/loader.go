@@ -0,0 +1,140 @@
+// Package loader parses a Slang file together with the files it includes with #add
+// and the modules it imports.
+package loader
+
+import (
+	"Goslang/ast"
+	"Goslang/lexer"
+	"Goslang/parser"
+	"Goslang/token"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Extension is added to include paths that are written without one, e.g.: #add "extra/functions"
+const Extension = ".slang"
+
+// Error is returned when a file or one of the files it includes cannot be loaded.
+type Error struct {
+	File     string //the file the messages belong to
+	Messages []string
+}
+
+func (e *Error) Error() string {
+	return e.File + ": " + strings.Join(e.Messages, "; ")
+}
+
+// Loader parses files and resolves their includes, every file is parsed once
+// and shared by all the files including it.
+type Loader struct {
+	programs map[string]*ast.Program //the parsed files by absolute path
+	loading  []string                //the files being loaded, the last one includes the next
+}
+
+func New() *Loader {
+	return &Loader{programs: map[string]*ast.Program{}}
+}
+
+// LoadFile reads and parses the file at path and the files it includes.
+func (l *Loader) LoadFile(path string) (*ast.Program, error) {
+	return l.loadFile(path, "include", token.Position{})
+}
+
+// loadFile loads the file at path for the directive, "include" or "import", that refers to it.
+// pos is the position of the directive, errors about the file itself are reported there.
+func (l *Loader) loadFile(path string, directive string, pos token.Position) (*ast.Program, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, &Error{File: path, Messages: []string{err.Error()}}
+	}
+	if program, ok := l.programs[absPath]; ok {
+		return program, nil
+	}
+
+	source, err := os.ReadFile(path)
+	if err != nil {
+		if pathErr, ok := err.(*os.PathError); ok {
+			err = pathErr.Err
+		}
+		return nil, &Error{File: path, Messages: []string{at(pos, fmt.Sprintf("cannot read file %s: %s", path, err))}}
+	}
+	return l.load(path, string(source), directive, pos)
+}
+
+// Load parses source as the contents of the file at path, includes and imports are
+// resolved relative to the directory of path.
+func (l *Loader) Load(path string, source string) (*ast.Program, error) {
+	return l.load(path, source, "include", token.Position{})
+}
+
+func (l *Loader) load(path string, source string, directive string, pos token.Position) (*ast.Program, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, &Error{File: path, Messages: []string{err.Error()}}
+	}
+
+	for i, loading := range l.loading {
+		if loading == absPath {
+			cycle := append(baseNames(l.loading[i:]), filepath.Base(path))
+			return nil, &Error{File: path, Messages: []string{at(pos, directive+" cycle: "+strings.Join(cycle, " -> "))}}
+		}
+	}
+	l.loading = append(l.loading, absPath)
+	defer func() { l.loading = l.loading[:len(l.loading)-1] }()
+
+	p := parser.New(lexer.NewFile(path, source))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, &Error{File: path, Messages: p.Errors()}
+	}
+
+	for _, statement := range program.Statements {
+		switch statement := statement.(type) {
+		case *ast.IncludeStatement:
+			included, err := l.loadFile(resolve(path, statement.Path), "include", statement.Pos())
+			if err != nil {
+				return nil, err
+			}
+			statement.Program = included
+		case *ast.ImportStatement:
+			imported, err := l.loadFile(resolve(path, statement.Path), "import", statement.Pos())
+			if err != nil {
+				return nil, err
+			}
+			statement.Program = imported
+		}
+	}
+
+	l.programs[absPath] = program
+	return program, nil
+}
+
+// at prefixes msg with pos, messages of the file given to the loader have no position.
+func at(pos token.Position, msg string) string {
+	if !pos.IsValid() {
+		return msg
+	}
+	return pos.String() + ": " + msg
+}
+
+// resolve returns the path of an included or imported file relative to the file referring to it.
+func resolve(includingFile string, includePath string) string {
+	if filepath.Ext(includePath) == "" {
+		includePath += Extension
+	}
+	if filepath.IsAbs(includePath) {
+		return includePath
+	}
+	return filepath.Join(filepath.Dir(includingFile), includePath)
+}
+
+// baseNames returns the file names of paths, used to keep include cycles readable.
+func baseNames(paths []string) []string {
+	names := []string{}
+	for _, path := range paths {
+		names = append(names, filepath.Base(path))
+	}
+	return names
+}
@@ -0,0 +1,135 @@
+package loader
+
+import (
+	"Goslang/ast"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadResolvesIncludesRelativeToTheIncludingFile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.slang":            `#add "extra/functions"; #add "extra/shapes.slang"`,
+		"extra/functions.slang": `#add "helpers" fn double(n:int):int { return n * 2; }`,
+		"extra/helpers.slang":   `var one = 1;`,
+		"extra/shapes.slang":    `#add "functions"`,
+	})
+
+	program, err := New().LoadFile(filepath.Join(dir, "main.slang"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	functions := program.Statements[0].(*ast.IncludeStatement).Program
+	shapes := program.Statements[1].(*ast.IncludeStatement).Program
+	if functions == nil || shapes == nil {
+		t.Fatalf("includes were not loaded")
+	}
+
+	helpers := functions.Statements[0].(*ast.IncludeStatement).Program
+	if helpers == nil || helpers.String() != "var one = 1;" {
+		t.Errorf("nested include was not loaded. got=%v", helpers)
+	}
+
+	if shapes.Statements[0].(*ast.IncludeStatement).Program != functions {
+		t.Errorf("a file included twice was parsed twice")
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.slang":       `#add "b"`,
+		"b.slang":       `#add "c"`,
+		"c.slang":       `#add "a"`,
+		"self.slang":    `#add "self"`,
+		"broken.slang":  `#add "syntax"`,
+		"syntax.slang":  `var = 5;`,
+		"missing.slang": `#add "nothing"`,
+		"geo.slang":     `import "geo" as self;`,
+		"nested.slang":  `if (truth) { #add "b" }`,
+		"local.slang":   `fn f() { import "geo" as g; return g; }`,
+	})
+
+	tests := []struct {
+		file            string
+		expectedFile    string
+		expectedMessage string
+	}{
+		{"a.slang", "a.slang", "c.slang:1:1: include cycle: a.slang -> b.slang -> c.slang -> a.slang"},
+		{"self.slang", "self.slang", "self.slang:1:1: include cycle: self.slang -> self.slang"},
+		{"broken.slang", "syntax.slang", "syntax.slang:1:5: expected next token to be 'IDENT', but got '=' instead"},
+		{"missing.slang", "nothing.slang", "missing.slang:1:1: cannot read file nothing.slang: no such file or directory"},
+		{"geo.slang", "geo.slang", "geo.slang:1:1: import cycle: geo.slang -> geo.slang"},
+		{"nested.slang", "nested.slang", "nested.slang:1:14: #add is only allowed at the top level of a file"},
+		{"local.slang", "local.slang", "local.slang:1:10: import is only allowed at the top level of a file"},
+	}
+
+	for _, tt := range tests {
+		_, err := New().LoadFile(filepath.Join(dir, tt.file))
+		loadErr, ok := err.(*Error)
+		if !ok {
+			t.Errorf("%s: expected a load error. got=%v", tt.file, err)
+			continue
+		}
+		if filepath.Base(loadErr.File) != tt.expectedFile {
+			t.Errorf("%s: wrong file. expected=%s, got=%s", tt.file, tt.expectedFile, loadErr.File)
+		}
+		//the positions name the files by their path, keep only the part inside dir
+		if len(loadErr.Messages) == 0 || strings.ReplaceAll(loadErr.Messages[0], dir+string(filepath.Separator), "") != tt.expectedMessage {
+			t.Errorf("%s: wrong message. expected=%q, got=%q", tt.file, tt.expectedMessage, loadErr.Messages)
+		}
+	}
+}
+
+func TestLoadResolvesImports(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.slang":          `import "math/geometry" as geo;`,
+		"math/geometry.slang": `#add "shared"`,
+		"math/shared.slang":   `var pi = 3.14;`,
+	})
+
+	program, err := New().LoadFile(filepath.Join(dir, "main.slang"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	module := program.Statements[0].(*ast.ImportStatement).Program
+	if module == nil {
+		t.Fatalf("import was not loaded")
+	}
+	if module.Statements[0].(*ast.IncludeStatement).Program == nil {
+		t.Errorf("include of the imported module was not loaded")
+	}
+}
+
+func TestLoadUsesTheGivenSource(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"lib.slang": `var one = 1;`,
+	})
+
+	program, err := New().Load(filepath.Join(dir, "main.slang"), `#add "lib" one;`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d", len(program.Statements))
+	}
+	if program.Statements[0].(*ast.IncludeStatement).Program == nil {
+		t.Errorf("include was not loaded")
+	}
+}
@@ -2,9 +2,9 @@ package main
 
 import (
 	"Goslang/evaluator"
-	"Goslang/lexer"
+	"Goslang/loader"
 	"Goslang/object"
-	"Goslang/parser"
+	"Goslang/typecheck"
 	"bufio"
 	"fmt"
 	"io"
@@ -33,18 +33,23 @@ func main() {
 	defer file.Close()
 
 	for _, line := range fileLines {
-		input.WriteString(line)
+		input.WriteString(line + "\n")
 	}
 	//input.WriteString("main();")
 	env := object.NewEnvironment()
-	l := lexer.New(input.String())
-	p := parser.New(l)
-	program := p.ParseProgram()
-	if len(p.Errors()) != 0 {
+	program, err := loader.New().Load(argFilePath, input.String())
+	if err != nil {
 		endTimeWithErrors := time.Now()
 		timeDiffWithErrors := endTimeWithErrors.Sub(startTime)
 		compileTimeCommentWithError := fmt.Sprintf("Compilation Time: %d Milliseconds\n", timeDiffWithErrors.Milliseconds())
-		printParserErrors(out, p.Errors(), compileTimeCommentWithError)
+		printParserErrors(out, loadErrorMessages(err), compileTimeCommentWithError)
+		return
+	}
+	if typeErrors := typecheck.New().Check(program); len(typeErrors) != 0 {
+		endTimeWithTypeErrors := time.Now()
+		timeDiffWithTypeErrors := endTimeWithTypeErrors.Sub(startTime)
+		compileTimeCommentWithTypeError := fmt.Sprintf("Compilation Time: %d Milliseconds\n", timeDiffWithTypeErrors.Milliseconds())
+		printParserErrors(out, typeErrors, compileTimeCommentWithTypeError)
 		return
 	}
 	evaluated := evaluator.Eval(program, env)
@@ -54,7 +59,7 @@ func main() {
 		timeDiffWithErrObj := endTimeWithErrObj.Sub(startTime)
 		compileTimeCommentWithErrObj := fmt.Sprintf("Compilation Time: %d Milliseconds\n", timeDiffWithErrObj.Milliseconds())
 		io.WriteString(out, compileTimeCommentWithErrObj)
-		io.WriteString(out, evaluated.Inspect())
+		io.WriteString(out, evaluated.(*object.Error).Traceback())
 		io.WriteString(out, "\n")
 		io.WriteString(out, "PROGRAM EXITED WITH CODE 1")
 		return
@@ -95,3 +100,11 @@ func printParserErrors(out io.Writer, errors []string, comment string) {
 	}
 	io.WriteString(out, "PROGRAM EXITED WITH CODE 1")
 }
+
+// loadErrorMessages returns the messages of a load error, they start with the position they belong to.
+func loadErrorMessages(err error) []string {
+	if loadErr, ok := err.(*loader.Error); ok {
+		return loadErr.Messages
+	}
+	return []string{err.Error()}
+}
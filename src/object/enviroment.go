@@ -1,23 +1,50 @@
 package object
 
+import "Goslang/ast"
+
+// Run holds the state of one run of a program, main.go and the REPL start one with
+// NewEnvironment and every environment of the run shares it.
+type Run struct {
+	Files map[*ast.Program]*Environment //the environment of every included or imported file that was evaluated
+	Stack []Frame                       //the calls of the functions being evaluated, the outermost first
+}
+
 func NewEnclosedEnvironment(outer *Environment) *Environment {
-	env := NewEnvironment()
+	env := NewRunEnvironment(outer.run)
 	env.outer = outer
 	return env
 }
 
+// NewEnvironment returns the environment of a new run.
 func NewEnvironment() *Environment {
+	return NewRunEnvironment(&Run{Files: map[*ast.Program]*Environment{}})
+}
+
+// NewRunEnvironment returns a top-level environment, e.g. of an included file, in an existing run.
+func NewRunEnvironment(run *Run) *Environment {
 	s := make(map[string]Object)
-	return &Environment{store: s, outer: nil}
+	return &Environment{store: s, private: map[string]bool{}, links: map[string]*Environment{}, outer: nil, run: run}
 }
 
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	store    map[string]Object
+	private  map[string]bool
+	links    map[string]*Environment //the names included from other files, bound in the environment of the file
+	outer    *Environment
+	receiver *Struct //the struct whose method is called in this scope, nil outside of methods
+	run      *Run
+}
+
+// Run returns the run the environment belongs to.
+func (e *Environment) Run() *Run {
+	return e.run
 }
 
 func (e *Environment) Get(name string) (Object, bool) {
 	value, ok := e.store[name]
+	if linked, isLinked := e.links[name]; !ok && isLinked {
+		value, ok = linked.Get(name)
+	}
 	if !ok && e.outer != nil {
 		value, ok = e.outer.Get(name)
 	}
@@ -26,5 +53,70 @@ func (e *Environment) Get(name string) (Object, bool) {
 
 func (e *Environment) Set(name string, value Object) Object {
 	e.store[name] = value
+	delete(e.private, name)
+	delete(e.links, name)
 	return value
 }
+
+// SetPrivate binds name like Set and marks it private to the file that declares it,
+// private names are not visible to the files that include it.
+func (e *Environment) SetPrivate(name string, value Object) Object {
+	e.store[name] = value
+	e.private[name] = true
+	delete(e.links, name)
+	return value
+}
+
+// IsPrivate reports whether name was declared private in this scope.
+func (e *Environment) IsPrivate(name string) bool {
+	return e.private[name]
+}
+
+// Include binds the names of file that were not declared private in this scope. The names
+// stay bound in file, so an assignment on either side is seen by both.
+func (e *Environment) Include(file *Environment) {
+	for name := range file.store {
+		if !file.private[name] {
+			e.link(name, file)
+		}
+	}
+	for name, linked := range file.links {
+		e.link(name, linked)
+	}
+}
+
+func (e *Environment) link(name string, file *Environment) {
+	delete(e.store, name)
+	delete(e.private, name)
+	e.links[name] = file
+}
+
+// Assign updates the binding of name in the scope that declares it.
+// It reports false if the name was never declared.
+func (e *Environment) Assign(name string, value Object) (Object, bool) {
+	if _, ok := e.store[name]; ok {
+		e.store[name] = value
+		return value, true
+	}
+	if linked, ok := e.links[name]; ok {
+		return linked.Assign(name, value)
+	}
+	if e.outer != nil {
+		return e.outer.Assign(name, value)
+	}
+	return nil, false
+}
+
+// SetReceiver records the struct whose method runs in this scope. It is kept apart from
+// the bindings so that user code cannot declare or assign it.
+func (e *Environment) SetReceiver(receiver *Struct) {
+	e.receiver = receiver
+}
+
+// Receiver returns the struct of the innermost method call enclosing this scope, or nil.
+func (e *Environment) Receiver() *Struct {
+	if e.receiver == nil && e.outer != nil {
+		return e.outer.Receiver()
+	}
+	return e.receiver
+}
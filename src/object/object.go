@@ -2,8 +2,12 @@ package object
 
 import (
 	"Goslang/ast"
+	"Goslang/token"
 	"bytes"
 	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
 	"strings"
 )
 
@@ -18,17 +22,31 @@ type BuiltinFunction func(args ...Object) Object
 
 const (
 	INTEGER_OBJ    = "INTEGER"
+	FLOAT_OBJ      = "FLOAT"
 	BOOLEAN_OBJ    = "BOOLEAN"
 	NULL_OBJ       = "NULL"
 	STRING_OBJ     = "STRING"
 	RETURN_VAL_OBJ = "RETURN_VAL"
+	BREAK_OBJ      = "BREAK"
+	CONTINUE_OBJ   = "CONTINUE"
 	ERROR_OBJ      = "ERROR"
 	FUNCTION_OBJ   = "FUNCTION"
 	BUILTIN_OBJ    = "BUILTIN"
 	ARRAY_OBJ      = "ARRAY"
+	HASH_OBJ       = "HASH"
+	STRUCT_OBJ     = "STRUCT"
+	STRUCT_DEF_OBJ = "STRUCT_DEFINITION"
+	METHOD_OBJ     = "METHOD"
+	INTERFACE_OBJ  = "INTERFACE"
+	MODULE_OBJ     = "MODULE"
 	PRINT_OBJ      = "PRINT"
 )
 
+// Iterable is implemented by the collection objects that foreach can walk
+type Iterable interface {
+	Items() []Object
+}
+
 // Integer object
 type Integer struct {
 	Value int64
@@ -37,6 +55,21 @@ type Integer struct {
 func (i *Integer) Inspect() string  { return fmt.Sprintf("%d", i.Value) }
 func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
 
+// Float object
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Inspect() string {
+	str := strconv.FormatFloat(f.Value, 'f', -1, 64)
+	// Keep whole floats distinguishable from integers, e.g. 3.0 instead of 3
+	if !math.IsInf(f.Value, 0) && !math.IsNaN(f.Value) && !strings.Contains(str, ".") {
+		str += ".0"
+	}
+	return str
+}
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+
 // Boolean object
 type Boolean struct {
 	Value bool
@@ -65,6 +98,15 @@ type String struct {
 func (s *String) Inspect() string  { return s.Value }
 func (s *String) Type() ObjectType { return STRING_OBJ }
 
+// Items returns the characters of the string
+func (s *String) Items() []Object {
+	var chars []Object
+	for _, ch := range s.Value {
+		chars = append(chars, &String{Value: string(ch)})
+	}
+	return chars
+}
+
 // ReturnVal object
 type ReturnVal struct {
 	Value Object
@@ -73,13 +115,64 @@ type ReturnVal struct {
 func (rv *ReturnVal) Type() ObjectType { return RETURN_VAL_OBJ }
 func (rv *ReturnVal) Inspect() string  { return rv.Value.Inspect() }
 
+// Break object, it stops the enclosing loop
+type Break struct{}
+
+func (b *Break) Type() ObjectType { return BREAK_OBJ }
+func (b *Break) Inspect() string  { return "break" }
+
+// Continue object, it skips to the next iteration of the enclosing loop
+type Continue struct{}
+
+func (c *Continue) Type() ObjectType { return CONTINUE_OBJ }
+func (c *Continue) Inspect() string  { return "continue" }
+
 // Error object
 type Error struct {
 	Message string
+	Kind    string         //e.g.: TYPE_ERROR, a caught error tells its kind in its type field
+	Pos     token.Position //where the error happened, set by the evaluator
+	Stack   []Frame        //the calls that led to the error, the outermost first
+}
+
+// The kinds of errors, THROWN_ERROR is the kind of the errors thrown with a message.
+const (
+	RUNTIME_ERROR = "RuntimeError"
+	TYPE_ERROR    = "TypeError"
+	NAME_ERROR    = "NameError"
+	INDEX_ERROR   = "IndexError"
+	VALUE_ERROR   = "ValueError"
+	THROWN_ERROR  = "Error"
+)
+
+// Frame is a call of a function, Pos is where the function was called.
+type Frame struct {
+	Function string
+	Pos      token.Position
 }
 
 func (e *Error) Type() ObjectType { return ERROR_OBJ }
-func (e *Error) Inspect() string  { return "ERROR:" + e.Message }
+func (e *Error) Inspect() string {
+	if e.Pos.IsValid() {
+		return "ERROR:" + e.Pos.String() + ": " + e.Message
+	}
+	return "ERROR:" + e.Message
+}
+
+// Traceback returns the calls that led to the error, the most recent last, followed by the error.
+func (e *Error) Traceback() string {
+	if len(e.Stack) == 0 {
+		return e.Inspect()
+	}
+
+	var out bytes.Buffer
+	out.WriteString("Traceback (most recent call last):\n")
+	for _, frame := range e.Stack {
+		out.WriteString("\t" + frame.Pos.String() + ": call to " + frame.Function + "\n")
+	}
+	out.WriteString(e.Inspect())
+	return out.String()
+}
 
 // Function object
 type Function struct {
@@ -96,7 +189,7 @@ func (f *Function) Inspect() string {
 	params := []string{}
 
 	for _, p := range f.Parameters {
-		params = append(params, p.Name.String()+":"+p.Type.String())
+		params = append(params, p.String())
 	}
 
 	out.WriteString("fn")
@@ -130,6 +223,13 @@ type Array struct {
 }
 
 func (ao *Array) Type() ObjectType { return ARRAY_OBJ }
+
+// Items returns a copy of the elements, so the array can be changed while iterating
+func (ao *Array) Items() []Object {
+	items := make([]Object, len(ao.Elements))
+	copy(items, ao.Elements)
+	return items
+}
 func (ao *Array) Inspect() string {
 	var out bytes.Buffer
 
@@ -146,6 +246,261 @@ func (ao *Array) Inspect() string {
 	return out.String()
 }
 
+// HashKey identifies a hashable object inside a Hash
+type HashKey struct {
+	Type  ObjectType
+	Value uint64
+}
+
+// Hashable is implemented by the objects that can be used as hash keys
+type Hashable interface {
+	HashKey() HashKey
+}
+
+func (i *Integer) HashKey() HashKey {
+	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
+}
+
+func (b *Boolean) HashKey() HashKey {
+	var value uint64
+	if b.Value {
+		value = 1
+	}
+	return HashKey{Type: b.Type(), Value: value}
+}
+
+func (s *String) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(s.Value))
+	return HashKey{Type: s.Type(), Value: h.Sum64()}
+}
+
+type HashPair struct {
+	Key   Object
+	Value Object
+}
+
+// Hash object, it keeps its pairs in insertion order
+type Hash struct {
+	Pairs map[HashKey]HashPair
+	Order []HashKey
+}
+
+func NewHash() *Hash {
+	return &Hash{Pairs: make(map[HashKey]HashPair)}
+}
+
+func (h *Hash) Set(key Hashable, value Object) {
+	hashKey := key.HashKey()
+	if _, ok := h.Pairs[hashKey]; !ok {
+		h.Order = append(h.Order, hashKey)
+	}
+	h.Pairs[hashKey] = HashPair{Key: key.(Object), Value: value}
+}
+
+func (h *Hash) Get(key Hashable) (Object, bool) {
+	pair, ok := h.Pairs[key.HashKey()]
+	if !ok {
+		return nil, false
+	}
+	return pair.Value, true
+}
+
+func (h *Hash) Delete(key Hashable) (Object, bool) {
+	hashKey := key.HashKey()
+	pair, ok := h.Pairs[hashKey]
+	if !ok {
+		return nil, false
+	}
+	delete(h.Pairs, hashKey)
+	for i, k := range h.Order {
+		if k == hashKey {
+			h.Order = append(h.Order[:i], h.Order[i+1:]...)
+			break
+		}
+	}
+	return pair.Value, true
+}
+
+// Items returns the keys of the hash
+func (h *Hash) Items() []Object {
+	keys := make([]Object, 0, len(h.Order))
+	for _, key := range h.Order {
+		keys = append(keys, h.Pairs[key].Key)
+	}
+	return keys
+}
+
+func (h *Hash) Type() ObjectType { return HASH_OBJ }
+func (h *Hash) Inspect() string {
+	var out bytes.Buffer
+
+	pairs := []string{}
+	for _, key := range h.Order {
+		pair := h.Pairs[key]
+		pairs = append(pairs, pair.Key.Inspect()+": "+pair.Value.Inspect())
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// StructDefinition object, the type declared by a struct statement
+type StructDefinition struct {
+	Name     string
+	Fields   []*ast.StructField
+	Methods  map[string]*Function
+	Access   map[string]string   //the access modifier of the fields and methods that declare one
+	Embedded []*StructDefinition //the structs embedded by the fields without a type
+}
+
+func (sd *StructDefinition) Type() ObjectType { return STRUCT_DEF_OBJ }
+func (sd *StructDefinition) Inspect() string {
+	var out bytes.Buffer
+
+	fields := []string{}
+	for _, field := range sd.Fields {
+		fields = append(fields, field.String())
+	}
+
+	out.WriteString("struct " + sd.Name + " { ")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+func (sd *StructDefinition) HasField(name string) bool {
+	for _, field := range sd.Fields {
+		if field.Name.Value == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Embeds reports whether other is embedded in the struct, directly or through another embedded struct.
+func (sd *StructDefinition) Embeds(other *StructDefinition) bool {
+	for _, embedded := range sd.Embedded {
+		if embedded == other || embedded.Embeds(other) {
+			return true
+		}
+	}
+	return false
+}
+
+// LookupMethod finds a method declared on the struct or promoted from an embedded struct.
+func (sd *StructDefinition) LookupMethod(name string) (*Function, bool) {
+	if method, ok := sd.Methods[name]; ok {
+		return method, true
+	}
+	for _, embedded := range sd.Embedded {
+		if method, ok := embedded.LookupMethod(name); ok {
+			return method, true
+		}
+	}
+	return nil, false
+}
+
+// Struct object, an instance of a StructDefinition
+type Struct struct {
+	Definition *StructDefinition
+	Fields     map[string]Object
+	Caught     *Error //the error a caught Error value was made from, rethrown as it is
+}
+
+func (s *Struct) Type() ObjectType { return STRUCT_OBJ }
+func (s *Struct) Inspect() string {
+	var out bytes.Buffer
+
+	fields := []string{}
+	for _, field := range s.Definition.Fields {
+		fields = append(fields, field.Name.String()+": "+s.Fields[field.Name.Value].Inspect())
+	}
+
+	out.WriteString(s.Definition.Name)
+	out.WriteString("{")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// BoundMethod object, a method together with the struct it was accessed on
+type BoundMethod struct {
+	Receiver *Struct
+	Method   *Function
+}
+
+func (bm *BoundMethod) Type() ObjectType { return METHOD_OBJ }
+func (bm *BoundMethod) Inspect() string  { return bm.Method.Inspect() }
+
+// Interface object, a set of method signatures structs satisfy by declaring them
+type Interface struct {
+	Name    string
+	Methods []*ast.InterfaceMethod
+}
+
+func (i *Interface) Type() ObjectType { return INTERFACE_OBJ }
+func (i *Interface) Inspect() string {
+	var out bytes.Buffer
+
+	methods := []string{}
+	for _, method := range i.Methods {
+		methods = append(methods, method.String())
+	}
+
+	out.WriteString("interface " + i.Name + " { ")
+	out.WriteString(strings.Join(methods, ", "))
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+// MissingMethods returns the methods of the interface the struct does not declare
+// with a matching signature, an empty result means the struct implements the interface.
+func (i *Interface) MissingMethods(sd *StructDefinition) []*ast.InterfaceMethod {
+	missing := []*ast.InterfaceMethod{}
+	for _, method := range i.Methods {
+		fn, ok := sd.LookupMethod(method.Name.Value)
+		if !ok || !sameSignature(method, fn) {
+			missing = append(missing, method)
+		}
+	}
+	return missing
+}
+
+func sameSignature(method *ast.InterfaceMethod, fn *Function) bool {
+	if len(method.Parameters) != len(fn.Parameters) {
+		return false
+	}
+	for idx, param := range method.Parameters {
+		if typeName(param.Type) != typeName(fn.Parameters[idx].Type) || param.Variadic != fn.Parameters[idx].Variadic {
+			return false
+		}
+	}
+	return typeName(method.ReturnType) == typeName(fn.ReturnType)
+}
+
+func typeName(t *ast.TypeAnnotation) string {
+	if t == nil {
+		return ""
+	}
+	return t.Value
+}
+
+// Module object, an imported file reached through its alias, e.g.: geo.area()
+type Module struct {
+	Name string
+	Env  *Environment
+}
+
+func (m *Module) Type() ObjectType { return MODULE_OBJ }
+func (m *Module) Inspect() string  { return "module " + m.Name }
+
 type PrintObject struct {
 	Elements []Object
 }
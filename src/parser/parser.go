@@ -5,12 +5,17 @@ import (
 	"Goslang/lexer"
 	"Goslang/token"
 	"fmt"
+	"path"
 	"strconv"
+	"strings"
 )
 
 const (
 	_int = iota
 	LOWEST
+	ASSIGN      // = or +=
+	LOGICAL_OR  // ||
+	LOGICAL_AND // &&
 	EQUALS      //==
 	LESSGREATER // > or <
 	BITWISE_AND_OR_XOR
@@ -38,21 +43,115 @@ var precedences = map[token.TokenType]int{
 	token.BITXOR:   BITWISE_AND_OR_XOR,
 	token.BITNOT:   PREFIX,
 	token.LBRACKET: INDEX,
+
+	token.ASSIGN:          ASSIGN,
+	token.PLUS_ASSIGN:     ASSIGN,
+	token.MINUS_ASSIGN:    ASSIGN,
+	token.ASTERISK_ASSIGN: ASSIGN,
+	token.SLASH_ASSIGN:    ASSIGN,
+	token.MODULUS_ASSIGN:  ASSIGN,
+	token.INCREMENT:       INDEX,
+	token.DECREMENT:       INDEX,
+
+	token.LT_EQ: LESSGREATER,
+	token.GT_EQ: LESSGREATER,
+	token.AND:   LOGICAL_AND,
+	token.OR:    LOGICAL_OR,
+
+	token.LBRACE: CALL,
+	token.DOT:    INDEX,
+}
+
+// ErrorCode tells what kind of syntax error a ParseError is.
+type ErrorCode string
+
+const (
+	UnexpectedToken     ErrorCode = "unexpected-token"
+	MissingExpression   ErrorCode = "missing-expression"
+	InvalidNumber       ErrorCode = "invalid-number"
+	MissingType         ErrorCode = "missing-type"
+	MissingBody         ErrorCode = "missing-body"
+	ExtraSemicolon      ErrorCode = "extra-semicolon"
+	InvalidAssignTarget ErrorCode = "invalid-assign-target"
+	Duplicate           ErrorCode = "duplicate-declaration"
+	InvalidModifier     ErrorCode = "invalid-modifier"
+	InvalidStructName   ErrorCode = "invalid-struct-name"
+	MissingCatch        ErrorCode = "missing-catch"
+	InvalidParameter    ErrorCode = "invalid-parameter"
+	InvalidArgument     ErrorCode = "invalid-argument"
+	IllegalToken        ErrorCode = "illegal-token"
+	InvalidPattern      ErrorCode = "invalid-pattern"
+	MisplacedDirective  ErrorCode = "misplaced-directive"
+)
+
+// ParseError is a syntax error, Expected and Got are set when a token was not the one expected.
+type ParseError struct {
+	Pos      token.Position
+	Code     ErrorCode
+	Expected token.TokenType
+	Got      token.TokenType
+	Message  string
+}
+
+func (e *ParseError) Error() string {
+	return e.Pos.String() + ": " + e.Message
 }
 
 // Errors A method for error handling of our parser :)
 func (p *Parser) Errors() []string {
+	messages := []string{}
+	for _, err := range p.errors {
+		messages = append(messages, err.Error())
+	}
+	return messages
+}
+
+// ParseErrors returns the syntax errors in the order they were found.
+func (p *Parser) ParseErrors() []*ParseError {
 	return p.errors
 }
 
 // This method is to add an error, whenever the expected token is not what I got.
 func (p *Parser) peekErrors(t token.TokenType) {
 	msg := fmt.Sprintf("expected next token to be '%s', but got '%s' instead", t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
+	p.unexpected(t, p.peekToken, msg)
+}
+
+// errorAt adds an error for the source at pos.
+func (p *Parser) errorAt(code ErrorCode, pos token.Position, msg string) {
+	p.addError(&ParseError{Pos: pos, Code: code, Message: msg})
+}
+
+// unexpected adds an error for the token got, expected is empty when any of several tokens would do.
+func (p *Parser) unexpected(expected token.TokenType, got token.Token, msg string) {
+	p.addError(&ParseError{Pos: got.Pos, Code: UnexpectedToken, Expected: expected, Got: got.Type, Message: msg})
+}
+
+// addError records err unless the statement already has an error that stopped the parser,
+// the errors that follow it in the statement are usually caused by it.
+func (p *Parser) addError(err *ParseError) {
+	if p.recovering {
+		return
+	}
+	p.errors = append(p.errors, err)
+
+	switch err.Code {
+	case Duplicate, MissingType, InvalidAssignTarget, InvalidNumber, InvalidParameter, InvalidArgument, MisplacedDirective:
+		//the parser goes on as if the code was right
+	default:
+		p.recovering = true
+	}
 }
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
 	p.peekToken = p.l.NextToken()
+
+	switch p.curToken.Type {
+	case token.LBRACE:
+		p.depth++
+	case token.RBRACE:
+		p.depth--
+	}
 }
 
 func (p *Parser) curTokenIs(t token.TokenType) bool {
@@ -76,7 +175,7 @@ func (p *Parser) expectPeek(t token.TokenType) bool {
 func (p *Parser) expectCurrent(t token.TokenType) {
 	if !p.curTokenIs(t) {
 		err := fmt.Sprintf("expected current token to be %s, but got %s instead", t, p.curToken.Type)
-		p.errors = append(p.errors, err)
+		p.unexpected(t, p.curToken, err)
 	}
 	p.nextToken()
 }
@@ -87,11 +186,14 @@ type (
 )
 type Parser struct {
 	l      *lexer.Lexer
-	errors []string
+	errors []*ParseError
 
 	curToken  token.Token
 	peekToken token.Token
 
+	depth      int  //the number of '{' before curToken that are not closed, curToken included
+	recovering bool //a statement has an error and was not synchronized yet
+
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
 }
@@ -102,9 +204,17 @@ func (p *Parser) registerPrefix(tokenType token.TokenType, fn prefixParseFn) {
 func (p *Parser) registerInfix(tokenType token.TokenType, fn infixParseFn) {
 	p.infixParseFns[tokenType] = fn
 }
+
+// parseIllegal reports the source the lexer could not read, the literal of an
+// ILLEGAL token describes the problem.
+func (p *Parser) parseIllegal() ast.Expression {
+	p.addError(&ParseError{Pos: p.curToken.Pos, Code: IllegalToken, Got: token.ILLEGAL, Message: p.curToken.Literal})
+	return nil
+}
+
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
 	msg := fmt.Sprintf("no prefix parser function found for %s", t)
-	p.errors = append(p.errors, msg)
+	p.addError(&ParseError{Pos: p.curToken.Pos, Code: MissingExpression, Got: t, Message: msg})
 }
 
 func (p *Parser) peekPrecedence() int {
@@ -124,12 +234,13 @@ func (p *Parser) curPrecedence() int {
 func New(l *lexer.Lexer) *Parser {
 	p := &Parser{
 		l:      l,
-		errors: []string{},
+		errors: []*ParseError{},
 	}
 	//prefix expression methods
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
 	p.registerPrefix(token.BITNOT, p.parsePrefixExpression)
@@ -137,8 +248,15 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.TRUTH, p.parseBoolean)
 	p.registerPrefix(token.LIE, p.parseBoolean)
 	p.registerPrefix(token.IF, p.parseIfExpression)
+	p.registerPrefix(token.MATCH, p.parseMatchExpression)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
+	p.registerPrefix(token.ILLEGAL, p.parseIllegal)
+	p.registerPrefix(token.TEMPLATE, p.parseTemplateLiteral)
 	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
+	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
+	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
+	p.registerPrefix(token.INCREMENT, p.parsePrefixIncrementExpression)
+	p.registerPrefix(token.DECREMENT, p.parsePrefixIncrementExpression)
 
 	//infix expression methods
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
@@ -150,6 +268,10 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.ASTERISK, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
+	p.registerInfix(token.GT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.LT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.AND, p.parseInfixExpression)
+	p.registerInfix(token.OR, p.parseInfixExpression)
 	p.registerInfix(token.EQ, p.parseInfixExpression)
 	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
 	p.registerInfix(token.BITAND, p.parseInfixExpression)
@@ -157,6 +279,16 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.BITXOR, p.parseInfixExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
+	p.registerInfix(token.ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.PLUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.MINUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.ASTERISK_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.SLASH_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.MODULUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.LBRACE, p.parseStructLiteral)
+	p.registerInfix(token.DOT, p.parseMemberExpression)
+	p.registerInfix(token.INCREMENT, p.parsePostfixIncrementExpression)
+	p.registerInfix(token.DECREMENT, p.parsePostfixIncrementExpression)
 
 	p.nextToken()
 	p.nextToken()
@@ -254,6 +386,126 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	return expression
 }
 
+func (p *Parser) parseMatchExpression() ast.Expression {
+	expression := &ast.MatchExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+	expression.Value = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		arm := p.parseMatchArm()
+		if arm == nil {
+			return nil
+		}
+		expression.Arms = append(expression.Arms, arm)
+
+		//the comma after an arm whose body is a block can be left out
+		if p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+		} else if _, ok := arm.Body.(*ast.BlockStatement); !ok && !p.peekTokenIs(token.RBRACE) {
+			msg := fmt.Sprintf("expected ',' or '}' after the match arm, got '%s' instead", p.peekToken.Literal)
+			p.unexpected(token.COMMA, p.peekToken, msg)
+			return nil
+		}
+	}
+	p.nextToken()
+
+	return expression
+}
+
+// parseMatchArm parses the patterns, the guard and the body of an arm, e.g.: 1 | 2 if x > 0 => body
+func (p *Parser) parseMatchArm() *ast.MatchArm {
+	arm := &ast.MatchArm{}
+
+	for {
+		pattern := p.parsePattern()
+		if pattern == nil {
+			return nil
+		}
+		arm.Patterns = append(arm.Patterns, pattern)
+		if !p.peekTokenIs(token.BITOR) {
+			break
+		}
+		p.nextToken()
+		p.nextToken()
+	}
+
+	if p.peekTokenIs(token.IF) {
+		p.nextToken()
+		p.nextToken()
+		arm.Guard = p.parseExpression(LOWEST)
+	}
+
+	if !p.expectPeek(token.ARROW) {
+		return nil
+	}
+
+	if p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
+		arm.Body = p.parseBlockStatement()
+		return arm
+	}
+	p.nextToken()
+	body := p.parseExpression(LOWEST)
+	if body == nil {
+		return nil
+	}
+	arm.Body = body
+	return arm
+}
+
+// parsePattern parses a pattern of a match arm: a literal, an array of patterns,
+// the _ wildcard or a name that binds the value.
+func (p *Parser) parsePattern() ast.Expression {
+	switch p.curToken.Type {
+	case token.INT, token.FLOAT, token.STRING, token.TRUTH, token.LIE, token.IDENT:
+		return p.prefixParseFns[p.curToken.Type]()
+
+	case token.MINUS:
+		if p.peekTokenIs(token.INT) || p.peekTokenIs(token.FLOAT) {
+			return p.parsePrefixExpression()
+		}
+
+	case token.LBRACKET:
+		array := &ast.ArrayLiteral{Token: p.curToken, Elements: []ast.Expression{}}
+		if p.peekTokenIs(token.RBRACKET) {
+			p.nextToken()
+			return array
+		}
+		for {
+			p.nextToken()
+			element := p.parsePattern()
+			if element == nil {
+				return nil
+			}
+			array.Elements = append(array.Elements, element)
+			if !p.peekTokenIs(token.COMMA) {
+				break
+			}
+			p.nextToken()
+		}
+		if !p.expectPeek(token.RBRACKET) {
+			return nil
+		}
+		return array
+	}
+
+	msg := fmt.Sprintf("expected a pattern, got '%s' instead", p.curToken.Literal)
+	p.addError(&ParseError{Pos: p.curToken.Pos, Code: InvalidPattern, Got: p.curToken.Type, Message: msg})
+	return nil
+}
+
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	block := &ast.BlockStatement{Token: p.curToken}
 	block.Statements = []ast.Statement{}
@@ -264,7 +516,7 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 		if p.curTokenIs(token.SEMICOLON) {
 			p.nextToken()
 		} else {
-			stmt := p.parseStatement()
+			stmt := p.parseStatementAndRecover()
 			if stmt != nil {
 				block.Statements = append(block.Statements, stmt)
 			}
@@ -279,36 +531,75 @@ func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 		Token:    p.curToken,
 		Function: function,
 	}
-	exp.Arguments = p.parseExpressionList(token.RPAREN)
+	exp.Arguments, exp.NamedArguments = p.parseCallArguments()
 	return exp
 }
 
-func (p *Parser) parseCallArguments() []ast.Expression {
+// parseCallArguments parses the arguments of a call, the positional ones and then
+// the ones passed by name, e.g.: draw(1, y: 2, color: "red")
+func (p *Parser) parseCallArguments() ([]ast.Expression, []*ast.NamedArgument) {
 	var args []ast.Expression
+	var named []*ast.NamedArgument
 
 	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
-		return args
+		return args, named
 	}
-	p.nextToken()
-	args = append(args, p.parseExpression(LOWEST))
 
-	for p.peekTokenIs(token.COMMA) {
+	for {
 		p.nextToken()
+		if p.curTokenIs(token.IDENT) && p.peekTokenIs(token.COLON) {
+			arg := &ast.NamedArgument{Name: &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}}
+			p.nextToken()
+			p.nextToken()
+			arg.Value = p.parseExpression(LOWEST)
+			named = append(named, arg)
+		} else {
+			if len(named) > 0 {
+				msg := fmt.Sprintf("positional argument follows named argument %s", named[len(named)-1].Name.Value)
+				p.errorAt(InvalidArgument, p.curToken.Pos, msg)
+			}
+			args = append(args, p.parseExpression(LOWEST))
+		}
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
 		p.nextToken()
-		args = append(args, p.parseExpression(LOWEST))
 	}
 
 	if !p.expectPeek(token.RPAREN) {
-		return nil
+		return nil, nil
 	}
-	return args
+	return args, named
 }
 
 func (p *Parser) parseStringLiteral() ast.Expression {
 	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
 }
 
+// parseTemplateLiteral parses a string with embedded expressions, the lexer splits it
+// in TEMPLATE parts followed by an expression and ends it with the TEMPLATE_END part.
+func (p *Parser) parseTemplateLiteral() ast.Expression {
+	lit := &ast.TemplateLiteral{Token: p.curToken}
+
+	for {
+		lit.Parts = append(lit.Parts, &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal})
+		if p.curTokenIs(token.TEMPLATE_END) {
+			return lit
+		}
+
+		p.nextToken()
+		lit.Parts = append(lit.Parts, p.parseExpression(LOWEST))
+
+		if !p.peekTokenIs(token.TEMPLATE) && !p.peekTokenIs(token.TEMPLATE_END) {
+			p.unexpected(token.RBRACE, p.peekToken, fmt.Sprintf("expected '}' after the embedded expression, got '%s' instead", p.peekToken.Literal))
+			return nil
+		}
+		p.nextToken()
+	}
+}
+
 /*
 	func (p *Parser) parsePrintExpression() ast.Expression {
 		expression := &ast.PrintExpression
@@ -319,7 +610,7 @@ func (p *Parser) ParseProgram() *ast.Program {
 	program.Statements = []ast.Statement{}
 
 	for !p.curTokenIs(token.EOF) {
-		stmt := p.parseStatement()
+		stmt := p.parseStatementAndRecover()
 		if stmt != nil {
 			program.Statements = append(program.Statements, stmt)
 		}
@@ -328,6 +619,66 @@ func (p *Parser) ParseProgram() *ast.Program {
 	return program
 }
 
+// statementKeywords are the tokens a statement can start with, the parser
+// synchronizes on them after a syntax error.
+var statementKeywords = map[token.TokenType]bool{
+	token.VAR:       true,
+	token.RETURN:    true,
+	token.FUNCTION:  true,
+	token.WHILE:     true,
+	token.DO:        true,
+	token.BREAK:     true,
+	token.CONTINUE:  true,
+	token.FOR:       true,
+	token.FOREACH:   true,
+	token.STRUCT:    true,
+	token.INTERFACE: true,
+	token.PUBLIC:    true,
+	token.PRIVATE:   true,
+	token.PROTECTED: true,
+	token.INCLUDE:   true,
+	token.IMPORT:    true,
+	token.THROW:     true,
+	token.TRY:       true,
+}
+
+// parseStatementAndRecover parses a statement, after a syntax error it skips the rest
+// of the statement so the errors of the next statements are reported too.
+func (p *Parser) parseStatementAndRecover() ast.Statement {
+	if p.recovering {
+		//a statement of a block in a statement with an error, the outer statement is skipped
+		return p.parseStatement()
+	}
+	depth := p.depth
+	if p.curTokenIs(token.LBRACE) {
+		depth--
+	}
+	stmt := p.parseStatement()
+	if p.recovering {
+		p.synchronize(depth)
+		p.recovering = false
+	}
+	return stmt
+}
+
+// synchronize skips tokens until the end of the statement that started at the given
+// brace depth: its ';', the '}' closing a brace the statement opened, or the token
+// before a '}' closing the enclosing block or a keyword starting the next statement.
+func (p *Parser) synchronize(depth int) {
+	for {
+		if p.depth == depth && p.curTokenIs(token.SEMICOLON) {
+			return
+		}
+		if p.depth == depth && p.curTokenIs(token.RBRACE) && !p.peekTokenIs(token.SEMICOLON) {
+			return
+		}
+		if p.peekTokenIs(token.EOF) || p.depth <= depth && (p.peekTokenIs(token.RBRACE) || statementKeywords[p.peekToken.Type]) {
+			return
+		}
+		p.nextToken()
+	}
+}
+
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.curToken.Type {
 	case token.VAR:
@@ -335,14 +686,44 @@ func (p *Parser) parseStatement() ast.Statement {
 	case token.RETURN:
 		return p.parseReturnStatement()
 	case token.FUNCTION:
-		return p.parseFunctionStatement()
+		//fn name() {} declares a function, fn() {} is an anonymous function literal
+		if p.peekTokenIs(token.IDENT) {
+			return p.parseFunctionStatement()
+		}
+		return p.parseExpressionStatement()
+	case token.WHILE:
+		return p.parseWhileStatement()
+	case token.DO:
+		return p.parseDoWhileStatement()
+	case token.BREAK:
+		return p.parseBreakStatement()
+	case token.CONTINUE:
+		return p.parseContinueStatement()
+	case token.FOR:
+		return p.parseForStatement()
+	case token.FOREACH:
+		return p.parseForeachStatement()
+	case token.STRUCT:
+		return p.parseStructStatement()
+	case token.INTERFACE:
+		return p.parseInterfaceStatement()
+	case token.PUBLIC, token.PRIVATE, token.PROTECTED:
+		return p.parseAccessModifier()
+	case token.INCLUDE:
+		return p.parseIncludeStatement()
+	case token.IMPORT:
+		return p.parseImportStatement()
+	case token.THROW:
+		return p.parseThrowStatement()
+	case token.TRY:
+		return p.parseTryStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
 }
 
 func (p *Parser) parseVarStatement() *ast.VarStatement {
-	stmt := &ast.VarStatement{Token: p.curToken}
+	stmt := &ast.VarStatement{Token: p.curToken, Doc: p.curToken.Doc}
 
 	if !p.expectPeek(token.IDENT) {
 		return nil
@@ -355,19 +736,26 @@ func (p *Parser) parseVarStatement() *ast.VarStatement {
 
 	p.nextToken()
 	stmt.Value = p.parseExpression(LOWEST)
-	//TODO: We skip the expressions until we encounter a semicolon
 
-	for !p.curTokenIs(token.SEMICOLON) {
+	if !p.expectSemicolon() {
+		return nil
+	}
+	return stmt
+}
+
+// expectSemicolon moves to the ';' ending a statement when there is one, the semicolon
+// is optional but a second one is an error.
+func (p *Parser) expectSemicolon() bool {
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
-	if !p.peekTokenIs(token.SEMICOLON) {
-		return stmt
-	} else {
+	if p.peekTokenIs(token.SEMICOLON) {
 		msg := fmt.Sprintf("compiling error: expected 1 semicolon ';' ")
-		p.errors = append(p.errors, msg)
-		return nil
+		p.errorAt(ExtraSemicolon, p.peekToken.Pos, msg)
+		p.nextToken()
+		return false
 	}
-	//return stmt
+	return true
 }
 
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
@@ -377,16 +765,10 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 
 	stmt.ReturnValue = p.parseExpression(LOWEST)
 
-	for !p.curTokenIs(token.SEMICOLON) {
-		p.nextToken()
-	}
-	if !p.peekTokenIs(token.SEMICOLON) {
-		return stmt
-	} else {
-		msg := fmt.Sprintf("compiling error: expected 1 semicolon ';' ")
-		p.errors = append(p.errors, msg)
+	if !p.expectSemicolon() {
 		return nil
 	}
+	return stmt
 }
 
 func (p *Parser) parseTypeAnnotation() *ast.TypeAnnotation {
@@ -401,13 +783,23 @@ func (p *Parser) parseTypeAnnotation() *ast.TypeAnnotation {
 
 func (p *Parser) parseFunctionStatement() *ast.FunctionStatement {
 	//funcStatement := ast.NewFunctionStatement()
-	stmt := &ast.FunctionStatement{Token: p.curToken}
+	stmt := &ast.FunctionStatement{Token: p.curToken, Doc: p.curToken.Doc}
 
 	if !p.expectPeek(token.IDENT) {
 		return nil
 	}
 	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 
+	//fn Point.area() declares a method of the Point struct
+	if p.peekTokenIs(token.DOT) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Receiver = stmt.Name
+		stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+
 	/*if stmt.Name.Value == "main" {
 		stmt.ReturnType = nil
 		stmt.Parameters = nil
@@ -415,43 +807,7 @@ func (p *Parser) parseFunctionStatement() *ast.FunctionStatement {
 	if !p.expectPeek(token.LPAREN) {
 		return nil
 	}
-	stmt.Parameters = []*ast.FunctionParameter{}
-	if p.curToken.Type != token.RPAREN {
-		for {
-			if p.curToken.Type == token.EOF || p.curToken.Type == token.RPAREN {
-				break
-			}
-			//	p.expectCurrent(token.LPAREN)
-			if p.curTokenIs(token.LPAREN) {
-				p.nextToken()
-			}
-			if p.curTokenIs(token.RPAREN) {
-				break
-			}
-			parameterName := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-			//stmt.Parameters = append(stmt.Parameters, parameterName)
-			p.nextToken()
-			var paramtype *ast.TypeAnnotation
-			if p.curTokenIs(token.COLON) {
-				p.nextToken()
-				paramtype = p.parseTypeAnnotation()
-			}
-			if paramtype == nil {
-				msg := fmt.Sprintf("Compile error: no parameter type declared")
-				p.errors = append(p.errors, msg)
-			}
-			param := &ast.FunctionParameter{
-				Name: parameterName,
-				Type: paramtype,
-			}
-			stmt.Parameters = append(stmt.Parameters, param)
-
-			if p.curToken.Type == token.COMMA {
-				p.nextToken()
-			}
-		}
-	}
-	p.expectCurrent(token.RPAREN)
+	stmt.Parameters = p.parseFunctionParameters()
 
 	if p.curTokenIs(token.COLON) {
 		p.nextToken()
@@ -460,7 +816,7 @@ func (p *Parser) parseFunctionStatement() *ast.FunctionStatement {
 	//Here I make the block statement parsing
 	if !p.curTokenIs(token.LBRACE) {
 		msg := fmt.Sprintf("function declaration error: expected left brace '{', got:%s missing function body.", p.curToken.Type)
-		p.errors = append(p.errors, msg)
+		p.errorAt(MissingBody, p.curToken.Pos, msg)
 		return nil
 	}
 	//That was false here
@@ -471,85 +827,330 @@ func (p *Parser) parseFunctionStatement() *ast.FunctionStatement {
 
 }
 
-func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
-	stmt := &ast.ExpressionStatement{Token: p.curToken}
-
-	stmt.Expression = p.parseExpression(LOWEST)
-
-	if p.peekTokenIs(token.SEMICOLON) {
-		p.nextToken()
+// checkParameter reports the parameters that cannot follow the previous ones: any after a
+// variadic one and the ones without a default value after one with a default value.
+func (p *Parser) checkParameter(param *ast.FunctionParameter, previous []*ast.FunctionParameter) {
+	name := param.Name.Value
+	switch {
+	case param.Variadic && param.Default != nil:
+		p.errorAt(InvalidParameter, param.Name.Pos(), fmt.Sprintf("variadic parameter %s cannot have a default value", name))
+	case len(previous) == 0:
+	case previous[len(previous)-1].Variadic:
+		p.errorAt(InvalidParameter, param.Name.Pos(), fmt.Sprintf("parameter %s follows the variadic parameter %s", name, previous[len(previous)-1].Name.Value))
+	case param.Default == nil && !param.Variadic && previous[len(previous)-1].Default != nil:
+		p.errorAt(InvalidParameter, param.Name.Pos(), fmt.Sprintf("parameter %s needs a default value, it follows a parameter with one", name))
 	}
-	return stmt
 }
 
-func (p *Parser) parseExpression(precedence int) ast.Expression {
-	prefix := p.prefixParseFns[p.curToken.Type]
-	if prefix == nil {
-		p.noPrefixParseFnError(p.curToken.Type)
-		return nil
-	}
-	leftExp := prefix()
-
-	for !p.peekTokenIs(token.SEMICOLON) && precedence < p.peekPrecedence() {
-		infix := p.infixParseFns[p.peekToken.Type]
-		if infix == nil {
-			return leftExp
+// parseFunctionParameters parses the parameters of a function starting on its '(' token,
+// it stops on the token after the closing ')'.
+func (p *Parser) parseFunctionParameters() []*ast.FunctionParameter {
+	parameters := []*ast.FunctionParameter{}
+	for {
+		if p.curToken.Type == token.EOF || p.curToken.Type == token.RPAREN {
+			break
+		}
+		//	p.expectCurrent(token.LPAREN)
+		if p.curTokenIs(token.LPAREN) {
+			p.nextToken()
+		}
+		if p.curTokenIs(token.RPAREN) {
+			break
+		}
+		variadic := p.curTokenIs(token.ELLIPSIS)
+		if variadic {
+			p.nextToken()
 		}
+		parameterName := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 		p.nextToken()
+		var paramtype *ast.TypeAnnotation
+		if p.curTokenIs(token.COLON) {
+			p.nextToken()
+			paramtype = p.parseTypeAnnotation()
+		}
+		if paramtype == nil {
+			msg := fmt.Sprintf("Compile error: no parameter type declared")
+			p.errorAt(MissingType, parameterName.Pos(), msg)
+		}
+		param := &ast.FunctionParameter{
+			Name:     parameterName,
+			Type:     paramtype,
+			Variadic: variadic,
+		}
+		if p.curTokenIs(token.ASSIGN) {
+			p.nextToken()
+			param.Default = p.parseExpression(LOWEST)
+			p.nextToken()
+		}
+		p.checkParameter(param, parameters)
+		parameters = append(parameters, param)
 
-		leftExp = infix(leftExp)
+		if p.curToken.Type == token.COMMA {
+			p.nextToken()
+		}
 	}
-	return leftExp
+	p.expectCurrent(token.RPAREN)
 
-	return leftExp
+	return parameters
 }
 
-func (p *Parser) parseIntegerLiteral() ast.Expression {
-	lit := &ast.IntegerLiteral{Token: p.curToken}
+func (p *Parser) parseFunctionLiteral() ast.Expression {
+	lit := &ast.FunctionLiteral{Token: p.curToken}
 
-	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
-	if err != nil {
-		msg := fmt.Sprintf("couldn't parse %q as an integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+	if !p.expectPeek(token.LPAREN) {
 		return nil
 	}
-	lit.Value = value
-	return lit
-}
+	lit.Parameters = p.parseFunctionParameters()
 
-func (p *Parser) parseArrayLiteral() ast.Expression {
-	array := &ast.ArrayLiteral{Token: p.curToken}
+	if p.curTokenIs(token.COLON) {
+		p.nextToken()
+		lit.ReturnType = p.parseTypeAnnotation()
+	}
 
-	array.Elements = p.parseExpressionList(token.RBRACKET)
+	if !p.curTokenIs(token.LBRACE) {
+		msg := fmt.Sprintf("function literal error: expected left brace '{', got:%s missing function body.", p.curToken.Type)
+		p.errorAt(MissingBody, p.curToken.Pos, msg)
+		return nil
+	}
+	lit.Block = p.parseBlockStatement()
 
-	return array
+	return lit
 }
 
-func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
-	var list []ast.Expression
+func (p *Parser) parseWhileStatement() *ast.WhileStatement {
+	stmt := &ast.WhileStatement{Token: p.curToken}
 
-	if p.peekTokenIs(end) {
-		p.nextToken()
-		return list
+	if !p.expectPeek(token.LPAREN) {
+		return nil
 	}
 	p.nextToken()
-	list = append(list, p.parseExpression(LOWEST))
+	stmt.Condition = p.parseExpression(LOWEST)
 
-	for p.peekTokenIs(token.COMMA) {
-		p.nextToken()
-		p.nextToken()
-		list = append(list, p.parseExpression(LOWEST))
+	if !p.expectPeek(token.RPAREN) {
+		return nil
 	}
 
-	if !p.expectPeek(end) {
+	if !p.expectPeek(token.LBRACE) {
 		return nil
 	}
 
-	return list
+	stmt.Block = p.parseBlockStatement()
+
+	return stmt
 }
 
-func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
-	exp := &ast.IndexExpression{Token: p.curToken, Left: left}
+func (p *Parser) parseDoWhileStatement() *ast.DoWhileStatement {
+	stmt := &ast.DoWhileStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Block = p.parseBlockStatement()
+
+	if !p.expectPeek(token.WHILE) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+	stmt.Condition = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseForStatement() *ast.ForStatement {
+	stmt := &ast.ForStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+
+	if !p.curTokenIs(token.SEMICOLON) {
+		stmt.Init = p.parseStatement()
+		// var statements stop on their semicolon, expressions right before it
+		if !p.curTokenIs(token.SEMICOLON) && !p.expectPeek(token.SEMICOLON) {
+			return nil
+		}
+	}
+
+	if !p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+		stmt.Condition = p.parseExpression(LOWEST)
+	}
+
+	if !p.expectPeek(token.SEMICOLON) {
+		return nil
+	}
+
+	if !p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		stmt.Update = p.parseExpression(LOWEST)
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Block = p.parseBlockStatement()
+
+	return stmt
+}
+
+func (p *Parser) parseForeachStatement() *ast.ForeachStatement {
+	stmt := &ast.ForeachStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Variable = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if !p.expectPeek(token.IN) {
+		return nil
+	}
+	p.nextToken()
+	stmt.Collection = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Block = p.parseBlockStatement()
+
+	return stmt
+}
+
+func (p *Parser) parseBreakStatement() *ast.BreakStatement {
+	stmt := &ast.BreakStatement{Token: p.curToken}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *Parser) parseContinueStatement() *ast.ContinueStatement {
+	stmt := &ast.ContinueStatement{Token: p.curToken}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
+	stmt := &ast.ExpressionStatement{Token: p.curToken}
+
+	stmt.Expression = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *Parser) parseExpression(precedence int) ast.Expression {
+	prefix := p.prefixParseFns[p.curToken.Type]
+	if prefix == nil {
+		p.noPrefixParseFnError(p.curToken.Type)
+		return nil
+	}
+	leftExp := prefix()
+
+	for !p.peekTokenIs(token.SEMICOLON) && precedence < p.peekPrecedence() {
+		infix := p.infixParseFns[p.peekToken.Type]
+		if infix == nil {
+			return leftExp
+		}
+		p.nextToken()
+
+		leftExp = infix(leftExp)
+	}
+	return leftExp
+}
+
+func (p *Parser) parseIntegerLiteral() ast.Expression {
+	lit := &ast.IntegerLiteral{Token: p.curToken}
+
+	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	if err != nil {
+		msg := fmt.Sprintf("couldn't parse %q as an integer", p.curToken.Literal)
+		p.errorAt(InvalidNumber, p.curToken.Pos, msg)
+		return nil
+	}
+	lit.Value = value
+	return lit
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{Token: p.curToken}
+
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	if err != nil {
+		msg := fmt.Sprintf("couldn't parse %q as a float", p.curToken.Literal)
+		p.errorAt(InvalidNumber, p.curToken.Pos, msg)
+		return nil
+	}
+	lit.Value = value
+	return lit
+}
+
+func (p *Parser) parseArrayLiteral() ast.Expression {
+	array := &ast.ArrayLiteral{Token: p.curToken}
+
+	array.Elements = p.parseExpressionList(token.RBRACKET)
+
+	return array
+}
+
+func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
+	var list []ast.Expression
+
+	if p.peekTokenIs(end) {
+		p.nextToken()
+		return list
+	}
+	p.nextToken()
+	list = append(list, p.parseExpression(LOWEST))
+
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		p.nextToken()
+		list = append(list, p.parseExpression(LOWEST))
+	}
+
+	if !p.expectPeek(end) {
+		return nil
+	}
+
+	return list
+}
+
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	exp := &ast.IndexExpression{Token: p.curToken, Left: left}
 
 	p.nextToken()
 	exp.Index = p.parseExpression(LOWEST)
@@ -559,3 +1160,430 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	}
 	return exp
 }
+
+func (p *Parser) parseHashLiteral() ast.Expression {
+	hash := &ast.HashLiteral{Token: p.curToken}
+	hash.Pairs = make(map[ast.Expression]ast.Expression)
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		key := p.parseExpression(LOWEST)
+
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+
+		p.nextToken()
+		value := p.parseExpression(LOWEST)
+
+		hash.Keys = append(hash.Keys, key)
+		hash.Pairs[key] = value
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+
+	return hash
+}
+
+func (p *Parser) parseAssignExpression(target ast.Expression) ast.Expression {
+	expression := &ast.AssignExpression{
+		Token:    p.curToken,
+		Target:   target,
+		Operator: p.curToken.Literal,
+	}
+	if !p.checkAssignTarget(target) {
+		return nil
+	}
+	p.nextToken()
+	//assignments are right associative: a = b = c is a = (b = c)
+	expression.Value = p.parseExpression(ASSIGN - 1)
+
+	return expression
+}
+
+func (p *Parser) parsePrefixIncrementExpression() ast.Expression {
+	expression := &ast.IncrementExpression{
+		Token:    p.curToken,
+		Operator: p.curToken.Literal,
+		Prefix:   true,
+	}
+	p.nextToken()
+	expression.Target = p.parseExpression(PREFIX)
+	if !p.checkAssignTarget(expression.Target) {
+		return nil
+	}
+
+	return expression
+}
+
+func (p *Parser) parsePostfixIncrementExpression(target ast.Expression) ast.Expression {
+	if !p.checkAssignTarget(target) {
+		return nil
+	}
+	return &ast.IncrementExpression{
+		Token:    p.curToken,
+		Target:   target,
+		Operator: p.curToken.Literal,
+	}
+}
+
+// checkAssignTarget reports an error unless the expression is something a value can be assigned to.
+func (p *Parser) checkAssignTarget(target ast.Expression) bool {
+	switch target.(type) {
+	case *ast.Identifier, *ast.IndexExpression, *ast.MemberExpression:
+		return true
+	}
+	if target != nil {
+		msg := fmt.Sprintf("invalid assignment target: %s", target.String())
+		p.errorAt(InvalidAssignTarget, target.Pos(), msg)
+	}
+	return false
+}
+
+func (p *Parser) parseStructStatement() *ast.StructStatement {
+	stmt := &ast.StructStatement{Token: p.curToken, Doc: p.curToken.Doc}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	p.nextToken()
+
+	declared := map[string]bool{}
+	for !p.curTokenIs(token.RBRACE) {
+		access := ""
+		if p.curTokenIs(token.PUBLIC) || p.curTokenIs(token.PRIVATE) || p.curTokenIs(token.PROTECTED) {
+			access = p.curToken.Literal
+			p.nextToken()
+		}
+		if !p.curTokenIs(token.IDENT) {
+			msg := fmt.Sprintf("expected field name in struct %s, but got '%s' instead", stmt.Name.Value, p.curToken.Type)
+			p.unexpected(token.IDENT, p.curToken, msg)
+			return nil
+		}
+		field := &ast.StructField{Access: access, Name: &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}}
+		if declared[field.Name.Value] {
+			msg := fmt.Sprintf("duplicate field %s in struct %s", field.Name.Value, stmt.Name.Value)
+			p.errorAt(Duplicate, p.curToken.Pos, msg)
+		}
+		declared[field.Name.Value] = true
+
+		if p.peekTokenIs(token.COLON) {
+			p.nextToken()
+			p.nextToken()
+			field.Type = p.parseTypeAnnotation()
+		} else {
+			//a field without a type embeds the struct it names
+			field.Type = &ast.TypeAnnotation{Token: p.curToken, Value: p.curToken.Literal}
+			field.Embedded = true
+			p.nextToken()
+		}
+		stmt.Fields = append(stmt.Fields, field)
+
+		if p.curTokenIs(token.COMMA) || p.curTokenIs(token.SEMICOLON) {
+			p.nextToken()
+		}
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseIncludeStatement() *ast.IncludeStatement {
+	stmt := &ast.IncludeStatement{Token: p.curToken}
+	//the loader only resolves the files of the top-level statements
+	if p.depth > 0 {
+		p.errorAt(MisplacedDirective, stmt.Token.Pos, "#add is only allowed at the top level of a file")
+	}
+
+	if !p.expectPeek(token.STRING) {
+		return nil
+	}
+	stmt.Path = p.curToken.Literal
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// parseImportStatement parses import "math/geometry" as geo; without an alias
+// the module is named after its file, e.g.: geometry
+func (p *Parser) parseImportStatement() *ast.ImportStatement {
+	stmt := &ast.ImportStatement{Token: p.curToken}
+	if p.depth > 0 {
+		p.errorAt(MisplacedDirective, stmt.Token.Pos, "import is only allowed at the top level of a file")
+	}
+
+	if !p.expectPeek(token.STRING) {
+		return nil
+	}
+	stmt.Path = p.curToken.Literal
+
+	if p.peekTokenIs(token.AS) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Alias = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	} else {
+		name := strings.TrimSuffix(path.Base(stmt.Path), path.Ext(stmt.Path))
+		stmt.Alias = &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: name, Pos: p.curToken.Pos}, Value: name}
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// parseAccessModifier parses a declaration that starts with public, private or protected,
+// the modifier is stored on the declared var, fn, struct or interface.
+func (p *Parser) parseAccessModifier() ast.Statement {
+	access := p.curToken.Literal
+	doc := p.curToken.Doc
+	p.nextToken()
+	p.curToken.Doc = doc //the comment above the modifier documents the declaration
+
+	switch p.curToken.Type {
+	case token.VAR:
+		if access == "protected" {
+			break
+		}
+		stmt := p.parseVarStatement()
+		if stmt == nil {
+			return nil
+		}
+		stmt.Access = access
+		return stmt
+	case token.FUNCTION:
+		if !p.peekTokenIs(token.IDENT) {
+			break
+		}
+		stmt := p.parseFunctionStatement()
+		if stmt == nil {
+			return nil
+		}
+		if access == "protected" && stmt.Receiver == nil {
+			msg := fmt.Sprintf("protected can only be used on struct fields and methods, %s is a function", stmt.Name.Value)
+			p.errorAt(InvalidModifier, stmt.Name.Pos(), msg)
+			return nil
+		}
+		stmt.Access = access
+		return stmt
+	case token.STRUCT:
+		if access == "protected" {
+			break
+		}
+		stmt := p.parseStructStatement()
+		if stmt == nil {
+			return nil
+		}
+		stmt.Access = access
+		return stmt
+	case token.INTERFACE:
+		if access == "protected" {
+			break
+		}
+		stmt := p.parseInterfaceStatement()
+		if stmt == nil {
+			return nil
+		}
+		stmt.Access = access
+		return stmt
+	}
+
+	msg := fmt.Sprintf("expected a declaration after %s, got '%s' instead", access, p.curToken.Literal)
+	if access == "protected" {
+		msg = fmt.Sprintf("protected can only be used on struct fields and methods, got '%s'", p.curToken.Literal)
+	}
+	p.addError(&ParseError{Pos: p.curToken.Pos, Code: InvalidModifier, Got: p.curToken.Type, Message: msg})
+	return nil
+}
+
+func (p *Parser) parseInterfaceStatement() *ast.InterfaceStatement {
+	stmt := &ast.InterfaceStatement{Token: p.curToken, Doc: p.curToken.Doc}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	p.nextToken()
+
+	declared := map[string]bool{}
+	for !p.curTokenIs(token.RBRACE) {
+		if !p.curTokenIs(token.IDENT) {
+			msg := fmt.Sprintf("expected method name in interface %s, but got '%s' instead", stmt.Name.Value, p.curToken.Type)
+			p.unexpected(token.IDENT, p.curToken, msg)
+			return nil
+		}
+		method := &ast.InterfaceMethod{Name: &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}}
+		if declared[method.Name.Value] {
+			msg := fmt.Sprintf("duplicate method %s in interface %s", method.Name.Value, stmt.Name.Value)
+			p.errorAt(Duplicate, p.curToken.Pos, msg)
+		}
+		declared[method.Name.Value] = true
+
+		if !p.expectPeek(token.LPAREN) {
+			return nil
+		}
+		method.Parameters = p.parseFunctionParameters()
+
+		if p.curTokenIs(token.COLON) {
+			p.nextToken()
+			method.ReturnType = p.parseTypeAnnotation()
+		}
+		stmt.Methods = append(stmt.Methods, method)
+
+		if p.curTokenIs(token.COMMA) || p.curTokenIs(token.SEMICOLON) {
+			p.nextToken()
+		}
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseStructLiteral(left ast.Expression) ast.Expression {
+	lit := &ast.StructLiteral{Token: p.curToken}
+	switch left := left.(type) {
+	case *ast.Identifier:
+		lit.Name = left
+	case *ast.MemberExpression:
+		//geo.Point{} creates a struct declared in the module imported as geo
+		module, ok := left.Object.(*ast.Identifier)
+		if !ok {
+			msg := fmt.Sprintf("expected struct name before '{', got %s", left.String())
+			p.errorAt(InvalidStructName, left.Pos(), msg)
+			return nil
+		}
+		lit.Module = module
+		lit.Name = left.Property
+	default:
+		msg := fmt.Sprintf("expected struct name before '{', got %s", left.String())
+		p.errorAt(InvalidStructName, left.Pos(), msg)
+		return nil
+	}
+
+	declared := map[string]bool{}
+	for !p.peekTokenIs(token.RBRACE) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		field := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		if declared[field.Value] {
+			msg := fmt.Sprintf("duplicate field %s in %s literal", field.Value, lit.Name.Value)
+			p.errorAt(Duplicate, p.curToken.Pos, msg)
+		}
+		declared[field.Value] = true
+
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+		p.nextToken()
+		lit.Fields = append(lit.Fields, field)
+		lit.Values = append(lit.Values, p.parseExpression(LOWEST))
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+
+	return lit
+}
+
+func (p *Parser) parseMemberExpression(object ast.Expression) ast.Expression {
+	exp := &ast.MemberExpression{Token: p.curToken, Object: object}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	exp.Property = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	return exp
+}
+
+func (p *Parser) parseThrowStatement() *ast.ThrowStatement {
+	stmt := &ast.ThrowStatement{Token: p.curToken}
+
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+	if stmt.Value == nil {
+		return nil
+	}
+
+	if !p.expectSemicolon() {
+		return nil
+	}
+	return stmt
+}
+
+// parseTryStatement parses try { } catch (e) { } finally { }, the name of the caught
+// error is optional and so is one of catch and finally.
+func (p *Parser) parseTryStatement() *ast.TryStatement {
+	stmt := &ast.TryStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	stmt.Block = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.CATCH) {
+		p.nextToken()
+		if p.peekTokenIs(token.LPAREN) {
+			p.nextToken()
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			stmt.CatchName = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			if !p.expectPeek(token.RPAREN) {
+				return nil
+			}
+		}
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+		stmt.CatchBlock = p.parseBlockStatement()
+	}
+
+	if p.peekTokenIs(token.FINALLY) {
+		p.nextToken()
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+		stmt.FinallyBlock = p.parseBlockStatement()
+	}
+
+	if stmt.CatchBlock == nil && stmt.FinallyBlock == nil {
+		msg := fmt.Sprintf("expected catch or finally after try, got '%s' instead", p.peekToken.Type)
+		p.addError(&ParseError{Pos: p.peekToken.Pos, Code: MissingCatch, Got: p.peekToken.Type, Message: msg})
+		return nil
+	}
+	return stmt
+}
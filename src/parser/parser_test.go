@@ -3,8 +3,11 @@ package parser
 import (
 	"Goslang/ast"
 	"Goslang/lexer"
+	"Goslang/token"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestVarStatements(t *testing.T) {
@@ -255,9 +258,13 @@ func testBooleanLiteral(t *testing.T, exp ast.Expression, value bool) bool {
 		t.Errorf("bo.Value not %t. got=%t", value, bo.Value)
 		return false
 	}
-	if bo.TokenLiteral() != fmt.Sprintf("%t", value) {
-		t.Errorf("bo.TokenLiteral not %t. got=%s",
-			value, bo.TokenLiteral())
+	literal := "lie"
+	if value {
+		literal = "truth"
+	}
+	if bo.TokenLiteral() != literal {
+		t.Errorf("bo.TokenLiteral not %s. got=%s",
+			literal, bo.TokenLiteral())
 		return false
 	}
 	return true
@@ -314,6 +321,34 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestFloatLiteralExpression(t *testing.T) {
+	input := "3.14;"
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if len(program.Statements) != 1 {
+		t.Fatalf("program has not enough statements. got=%d",
+			len(program.Statements))
+	}
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T",
+			program.Statements[0])
+	}
+	literal, ok := stmt.Expression.(*ast.FloatLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.FloatLiteral. got=%T", stmt.Expression)
+	}
+	if literal.Value != 3.14 {
+		t.Errorf("literal.Value not %f. got=%f", 3.14, literal.Value)
+	}
+	if literal.TokenLiteral() != "3.14" {
+		t.Errorf("literal.TokenLiteral not %s. got=%s", "3.14",
+			literal.TokenLiteral())
+	}
+}
+
 func TestParsingPrefixExpressions(t *testing.T) {
 	prefixTests := []struct {
 		input        string
@@ -387,6 +422,10 @@ func TestParsingInfixExpressions(t *testing.T) {
 		{"5 > 5;", 5, ">", 5},
 		{"5 == 5;", 5, "==", 5},
 		{"5 != 5;", 5, "!=", 5},
+		{"5 <= 5;", 5, "<=", 5},
+		{"5 >= 5;", 5, ">=", 5},
+		{"a && b;", "a", "&&", "b"},
+		{"a || b;", "a", "||", "b"},
 		{"2 ^ 2;", 2, "^", 2},
 
 		{"truth == truth", true, "==", true},
@@ -501,6 +540,42 @@ func TestOperatorPrecedence(t *testing.T) {
 			"add(a * b[2], b[1], 2 * [1, 2][1])",
 			"add((a * (b[2])),(b[1]),(2 * ([1, 2][1])))",
 		},
+		{
+			"x >= 0 && x <= 10",
+			"((x >= 0) && (x <= 10))",
+		},
+		{
+			"a || b && c == d",
+			"(a || (b && (c == d)))",
+		},
+		{
+			"a = b || c",
+			"a = (b || c)",
+		},
+		{
+			"p.x + p.y * 2",
+			"(p.x + (p.y * 2))",
+		},
+		{
+			"a.b.c(1)[0]",
+			"(a.b.c(1)[0])",
+		},
+		{
+			"-p.x",
+			"(-p.x)",
+		},
+		{
+			"a = b = c + 1",
+			"a = b = (c + 1)",
+		},
+		{
+			"a[i + 1] *= 2",
+			"(a[(i + 1)]) *= 2",
+		},
+		{
+			"-a++ + --b",
+			"((-(a++)) + (--b))",
+		},
 	}
 
 	for _, tt := range tests {
@@ -703,6 +778,80 @@ func TestStringLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestTemplateLiteralExpression(t *testing.T) {
+	input := `"Hello ${name}, you have ${count + 1} items";`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	literal, ok := stmt.Expression.(*ast.TemplateLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.TemplateLiteral. got=%T", stmt.Expression)
+	}
+	if len(literal.Parts) != 5 {
+		t.Fatalf("literal.Parts has wrong length. got=%d", len(literal.Parts))
+	}
+	for i, text := range []string{"Hello ", ", you have ", " items"} {
+		part, ok := literal.Parts[i*2].(*ast.StringLiteral)
+		if !ok || part.Value != text {
+			t.Errorf("literal.Parts[%d] is not %q. got=%v", i*2, text, literal.Parts[i*2])
+		}
+	}
+	testIdentifier(t, literal.Parts[1], "name")
+	testInfixExpression(t, literal.Parts[3], "count", "+", 1)
+	if literal.String() != `"Hello ${name}, you have ${(count + 1)} items"` {
+		t.Errorf("literal.String() wrong. got=%q", literal.String())
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	input := `match (x) {
+	1 | -2 => "small",
+	[a, _] if a > 0 => { a; }
+	_ => lie
+}`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.MatchExpression)
+	if !ok {
+		t.Fatalf("exp not *ast.MatchExpression. got=%T", stmt.Expression)
+	}
+	testIdentifier(t, exp.Value, "x")
+	if len(exp.Arms) != 3 {
+		t.Fatalf("exp.Arms has wrong length. got=%d", len(exp.Arms))
+	}
+
+	first := exp.Arms[0]
+	if len(first.Patterns) != 2 || first.Guard != nil {
+		t.Fatalf("wrong first arm. got=%q", first.String())
+	}
+	testIntegerLiteral(t, first.Patterns[0], 1)
+	if first.Patterns[1].String() != "(-2)" {
+		t.Errorf("wrong second pattern. got=%q", first.Patterns[1].String())
+	}
+
+	second := exp.Arms[1]
+	array, ok := second.Patterns[0].(*ast.ArrayLiteral)
+	if !ok || len(array.Elements) != 2 {
+		t.Fatalf("pattern is not an array of 2 patterns. got=%T", second.Patterns[0])
+	}
+	testIdentifier(t, array.Elements[0], "a")
+	testIdentifier(t, array.Elements[1], "_")
+	testInfixExpression(t, second.Guard, "a", ">", 0)
+	if _, ok := second.Body.(*ast.BlockStatement); !ok {
+		t.Errorf("body of the second arm is not *ast.BlockStatement. got=%T", second.Body)
+	}
+
+	testIdentifier(t, exp.Arms[2].Patterns[0], "_")
+	if body, ok := exp.Arms[2].Body.(*ast.Boolean); !ok || body.Value {
+		t.Errorf("body of the last arm is not lie. got=%q", exp.Arms[2].Body.String())
+	}
+}
+
 func TestParsingArrayLiterals(t *testing.T) {
 	input := "[1, 2 * 2, 3 + 3]"
 	l := lexer.New(input)
@@ -740,3 +889,910 @@ func TestParsingIndexExpressions(t *testing.T) {
 		return
 	}
 }
+
+func TestParsingHashLiterals(t *testing.T) {
+	input := `{"one": 1, "two": 2, "three": 3}`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+	if len(hash.Pairs) != 3 {
+		t.Errorf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
+	expected := []struct {
+		key   string
+		value int64
+	}{
+		{"one", 1},
+		{"two", 2},
+		{"three", 3},
+	}
+	for i, tt := range expected {
+		literal, ok := hash.Keys[i].(*ast.StringLiteral)
+		if !ok {
+			t.Errorf("key is not ast.StringLiteral. got=%T", hash.Keys[i])
+			continue
+		}
+		if literal.Value != tt.key {
+			t.Errorf("key has wrong value. expected=%q, got=%q", tt.key, literal.Value)
+		}
+		testIntegerLiteral(t, hash.Pairs[hash.Keys[i]], tt.value)
+	}
+}
+
+func TestParsingEmptyHashLiteral(t *testing.T) {
+	input := "{}"
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+	if len(hash.Pairs) != 0 {
+		t.Errorf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
+}
+
+func TestParsingHashLiteralsWithExpressions(t *testing.T) {
+	input := `{"one": 0 + 1, "two": 10 - 8, 3: 15 / 5}`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+	if len(hash.Pairs) != 3 {
+		t.Fatalf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
+	testInfixExpression(t, hash.Pairs[hash.Keys[0]], 0, "+", 1)
+	testInfixExpression(t, hash.Pairs[hash.Keys[1]], 10, "-", 8)
+	testIntegerLiteral(t, hash.Keys[2], 3)
+	testInfixExpression(t, hash.Pairs[hash.Keys[2]], 15, "/", 5)
+}
+
+func TestWhileStatement(t *testing.T) {
+	input := `while (x < y) { x; break; continue; }`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d\n",
+			1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.WhileStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.WhileStatement. got=%T",
+			program.Statements[0])
+	}
+
+	if !testInfixExpression(t, stmt.Condition, "x", "<", "y") {
+		return
+	}
+
+	if len(stmt.Block.Statements) != 3 {
+		t.Fatalf("block is not 3 statements. got=%d\n", len(stmt.Block.Statements))
+	}
+
+	if _, ok := stmt.Block.Statements[1].(*ast.BreakStatement); !ok {
+		t.Errorf("Statements[1] is not ast.BreakStatement. got=%T", stmt.Block.Statements[1])
+	}
+
+	if _, ok := stmt.Block.Statements[2].(*ast.ContinueStatement); !ok {
+		t.Errorf("Statements[2] is not ast.ContinueStatement. got=%T", stmt.Block.Statements[2])
+	}
+}
+
+func TestDoWhileStatement(t *testing.T) {
+	input := `do { x; } while (x < y); y;`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d\n",
+			2, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.DoWhileStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.DoWhileStatement. got=%T",
+			program.Statements[0])
+	}
+
+	if len(stmt.Block.Statements) != 1 {
+		t.Fatalf("block is not 1 statements. got=%d\n", len(stmt.Block.Statements))
+	}
+
+	if !testInfixExpression(t, stmt.Condition, "x", "<", "y") {
+		return
+	}
+}
+
+func TestForStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"for (var i = 0; i < 10; f(i)) { i; }", "for (var i = 0; (i < 10); f(i)) i"},
+		{"for (x; x < 10; ) { x; }", "for (x; (x < 10); ) x"},
+		{"for (;;) { break; }", "for (; ; ) break;"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain %d statements. got=%d\n",
+				1, len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ForStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ForStatement. got=%T",
+				program.Statements[0])
+		}
+
+		if stmt.String() != tt.expected {
+			t.Errorf("expected:%q, got:%q", tt.expected, stmt.String())
+		}
+	}
+}
+
+func TestForeachStatement(t *testing.T) {
+	input := `foreach (x in [1, 2]) { x; }`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d\n",
+			1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ForeachStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ForeachStatement. got=%T",
+			program.Statements[0])
+	}
+
+	if stmt.Variable.Value != "x" {
+		t.Errorf("stmt.Variable.Value not %s. got=%s", "x", stmt.Variable.Value)
+	}
+
+	if _, ok := stmt.Collection.(*ast.ArrayLiteral); !ok {
+		t.Errorf("stmt.Collection is not ast.ArrayLiteral. got=%T", stmt.Collection)
+	}
+
+	if len(stmt.Block.Statements) != 1 {
+		t.Fatalf("block is not 1 statements. got=%d\n", len(stmt.Block.Statements))
+	}
+}
+
+func TestAssignExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		target   string
+		operator string
+	}{
+		{"x = 5;", "x", "="},
+		{"x += 5;", "x", "+="},
+		{"x -= 5;", "x", "-="},
+		{"x *= 5;", "x", "*="},
+		{"x /= 5;", "x", "/="},
+		{"x %= 5;", "x", "%="},
+		{"arr[0] = 5;", "(arr[0])", "="},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain %d statements. got=%d\n",
+				1, len(program.Statements))
+		}
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		exp, ok := stmt.Expression.(*ast.AssignExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.AssignExpression. got=%T", stmt.Expression)
+		}
+		if exp.Target.String() != tt.target {
+			t.Errorf("exp.Target is not %s. got=%s", tt.target, exp.Target.String())
+		}
+		if exp.Operator != tt.operator {
+			t.Errorf("exp.Operator is not '%s'. got=%s", tt.operator, exp.Operator)
+		}
+		testIntegerLiteral(t, exp.Value, 5)
+	}
+}
+
+func TestInvalidAssignTargets(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"5 = 1;", "1:1: invalid assignment target: 5"},
+		{"f() += 1;", "1:1: invalid assignment target: f()"},
+		{"(a + b)++;", "1:2: invalid assignment target: (a + b)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q", tt.input)
+			continue
+		}
+		if p.Errors()[0] != tt.expectedError {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expectedError, p.Errors()[0])
+		}
+	}
+}
+
+func TestFunctionLiteralParsing(t *testing.T) {
+	input := `var add = fn(x:int, y:int):int { return x + y; };`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d\n",
+			1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.VarStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.VarStatement. got=%T",
+			program.Statements[0])
+	}
+
+	function, ok := stmt.Value.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("stmt.Value is not ast.FunctionLiteral. got=%T", stmt.Value)
+	}
+
+	if len(function.Parameters) != 2 {
+		t.Fatalf("function literal parameters wrong. want 2, got=%d\n",
+			len(function.Parameters))
+	}
+
+	testLiteralExpression(t, function.Parameters[0].Name, "x")
+	testLiteralExpression(t, function.Parameters[1].Name, "y")
+
+	if function.Parameters[0].Type.Value != "int" {
+		t.Errorf("parameter type is not int. got=%s", function.Parameters[0].Type.Value)
+	}
+
+	if function.ReturnType == nil || function.ReturnType.Value != "int" {
+		t.Errorf("function.ReturnType is not int. got=%v", function.ReturnType)
+	}
+
+	if len(function.Block.Statements) != 1 {
+		t.Fatalf("function.Block.Statements has not 1 statements. got=%d\n",
+			len(function.Block.Statements))
+	}
+}
+
+func TestFunctionLiteralCallParsing(t *testing.T) {
+	input := `fn(x:int) { x; }(5);`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d\n",
+			1, len(program.Statements))
+	}
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.CallExpression. got=%T", stmt.Expression)
+	}
+
+	if _, ok := exp.Function.(*ast.FunctionLiteral); !ok {
+		t.Fatalf("exp.Function is not ast.FunctionLiteral. got=%T", exp.Function)
+	}
+
+	if len(exp.Arguments) != 1 {
+		t.Fatalf("wrong length of arguments. got=%d", len(exp.Arguments))
+	}
+}
+
+func TestStructStatement(t *testing.T) {
+	input := `struct Point { x:int, y:int, label:string };`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d\n",
+			1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.StructStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.StructStatement. got=%T",
+			program.Statements[0])
+	}
+
+	if stmt.Name.Value != "Point" {
+		t.Errorf("stmt.Name.Value not %s. got=%s", "Point", stmt.Name.Value)
+	}
+
+	expected := []struct {
+		name      string
+		fieldType string
+	}{
+		{"x", "int"},
+		{"y", "int"},
+		{"label", "string"},
+	}
+
+	if len(stmt.Fields) != len(expected) {
+		t.Fatalf("stmt.Fields has wrong length. want=%d, got=%d", len(expected), len(stmt.Fields))
+	}
+
+	for i, tt := range expected {
+		if stmt.Fields[i].Name.Value != tt.name {
+			t.Errorf("field name wrong. want=%s, got=%s", tt.name, stmt.Fields[i].Name.Value)
+		}
+		if stmt.Fields[i].Type.Value != tt.fieldType {
+			t.Errorf("field type wrong. want=%s, got=%s", tt.fieldType, stmt.Fields[i].Type.Value)
+		}
+	}
+}
+
+func TestStructLiteralParsing(t *testing.T) {
+	input := `Point{x: 1, y: 2 * 3}`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	lit, ok := stmt.Expression.(*ast.StructLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.StructLiteral. got=%T", stmt.Expression)
+	}
+
+	if lit.Name.Value != "Point" {
+		t.Errorf("lit.Name.Value not %s. got=%s", "Point", lit.Name.Value)
+	}
+
+	if len(lit.Fields) != 2 {
+		t.Fatalf("lit.Fields has wrong length. got=%d", len(lit.Fields))
+	}
+
+	testIdentifier(t, lit.Fields[0], "x")
+	testIntegerLiteral(t, lit.Values[0], 1)
+	testIdentifier(t, lit.Fields[1], "y")
+	testInfixExpression(t, lit.Values[1], 2, "*", 3)
+}
+
+func TestMethodStatement(t *testing.T) {
+	input := `fn Point.area():int { return self.x * self.y; }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.FunctionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.FunctionStatement. got=%T",
+			program.Statements[0])
+	}
+
+	if stmt.Receiver == nil || stmt.Receiver.Value != "Point" {
+		t.Fatalf("stmt.Receiver is not Point. got=%v", stmt.Receiver)
+	}
+
+	if stmt.Name.Value != "area" {
+		t.Errorf("stmt.Name.Value not %s. got=%s", "area", stmt.Name.Value)
+	}
+}
+
+func TestInterfaceStatement(t *testing.T) {
+	input := `interface Shape { area():int, scale(factor:float):Shape; name():string }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d\n",
+			1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.InterfaceStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.InterfaceStatement. got=%T",
+			program.Statements[0])
+	}
+
+	if stmt.Name.Value != "Shape" {
+		t.Errorf("stmt.Name.Value not %s. got=%s", "Shape", stmt.Name.Value)
+	}
+
+	expected := []string{"area():int", "scale(factor:float):Shape", "name():string"}
+
+	if len(stmt.Methods) != len(expected) {
+		t.Fatalf("stmt.Methods has wrong length. want=%d, got=%d", len(expected), len(stmt.Methods))
+	}
+
+	for i, signature := range expected {
+		if stmt.Methods[i].String() != signature {
+			t.Errorf("method signature wrong. want=%s, got=%s", signature, stmt.Methods[i].String())
+		}
+	}
+}
+
+func TestAccessModifiers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"private var x = 5;", "private var x = 5;"},
+		{"public fn f() {}", "public fn f() {\n}"},
+		{"private fn Point.f() {}", "private fn Point.f() {\n}"},
+		{"protected fn Point.f() {}", "protected fn Point.f() {\n}"},
+		{"private struct Point { x:int }", "private struct Point { x:int }"},
+		{"struct Account { private balance:int, protected owner:string, public id:int }",
+			"struct Account { private balance:int, protected owner:string, public id:int }"},
+		{"struct Dog { Animal, breed:string }", "struct Dog { Animal, breed:string }"},
+		{"private interface Shape { area():int }", "private interface Shape { area():int }"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain %d statements. got=%d\n",
+				1, len(program.Statements))
+		}
+		if program.Statements[0].String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.Statements[0].String())
+		}
+	}
+}
+
+func TestIncludeStatement(t *testing.T) {
+	input := `
+#add "extra/functions"
+#add "shapes";
+`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	expected := []string{"extra/functions", "shapes"}
+	if len(program.Statements) != len(expected) {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d\n",
+			len(expected), len(program.Statements))
+	}
+
+	for i, path := range expected {
+		stmt, ok := program.Statements[i].(*ast.IncludeStatement)
+		if !ok {
+			t.Fatalf("program.Statements[%d] is not ast.IncludeStatement. got=%T", i, program.Statements[i])
+		}
+		if stmt.Path != path {
+			t.Errorf("stmt.Path not %q. got=%q", path, stmt.Path)
+		}
+	}
+}
+
+func TestImportStatement(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedPath  string
+		expectedAlias string
+	}{
+		{`import "math/geometry" as geo;`, "math/geometry", "geo"},
+		{`import "math/geometry"`, "math/geometry", "geometry"},
+		{`import "shapes.slang";`, "shapes.slang", "shapes"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain %d statements. got=%d\n",
+				1, len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ImportStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ImportStatement. got=%T", program.Statements[0])
+		}
+		if stmt.Path != tt.expectedPath {
+			t.Errorf("stmt.Path not %q. got=%q", tt.expectedPath, stmt.Path)
+		}
+		testIdentifier(t, stmt.Alias, tt.expectedAlias)
+	}
+}
+
+func TestThrowStatement(t *testing.T) {
+	p := New(lexer.New(`throw "bad input";`))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ThrowStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ThrowStatement. got=%T", program.Statements[0])
+	}
+	if stmt.String() != `throw bad input;` {
+		t.Errorf("stmt.String() wrong. got=%q", stmt.String())
+	}
+}
+
+func TestTryStatement(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedCatch    string
+		expectedCatchSet bool
+		expectedFinally  bool
+	}{
+		{`try { x; } catch (e) { e; }`, "e", true, false},
+		{`try { x; } catch { y; } finally { z; }`, "", true, true},
+		{`try { x; } finally { z; }`, "", false, true},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+		stmt, ok := program.Statements[0].(*ast.TryStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.TryStatement. got=%T", program.Statements[0])
+		}
+		if stmt.Block.String() != "x" {
+			t.Errorf("try block wrong. got=%q", stmt.Block.String())
+		}
+		if (stmt.CatchBlock != nil) != tt.expectedCatchSet {
+			t.Errorf("catch block of %q wrong. got=%v", tt.input, stmt.CatchBlock)
+		}
+		if tt.expectedCatch == "" && stmt.CatchName != nil {
+			t.Errorf("catch name of %q should be nil. got=%s", tt.input, stmt.CatchName)
+		}
+		if tt.expectedCatch != "" {
+			testIdentifier(t, stmt.CatchName, tt.expectedCatch)
+		}
+		if (stmt.FinallyBlock != nil) != tt.expectedFinally {
+			t.Errorf("finally block of %q wrong. got=%v", tt.input, stmt.FinallyBlock)
+		}
+	}
+}
+
+func TestFunctionParameterDefaultsAndVariadic(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn f(x:int, y:int = 10) {}", "x:int, y:int = 10"},
+		{"fn f(x:int, y:int = x * 2, z:string = \"a\") {}", "x:int, y:int = (x * 2), z:string = a"},
+		{"fn f(x:int, ...rest:int) {}", "x:int, ...rest:int"},
+		{"fn f(x:int = 1, ...rest:int) {}", "x:int = 1, ...rest:int"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		fn := program.Statements[0].(*ast.FunctionStatement)
+		params := []string{}
+		for _, param := range fn.Parameters {
+			params = append(params, param.String())
+		}
+		if strings.Join(params, ", ") != tt.expected {
+			t.Errorf("wrong parameters for %q. expected=%q, got=%q", tt.input, tt.expected, strings.Join(params, ", "))
+		}
+	}
+
+	fn := New(lexer.New("fn(a:int, ...b:string) {}")).ParseProgram().Statements[0].(*ast.ExpressionStatement)
+	lit := fn.Expression.(*ast.FunctionLiteral)
+	if lit.Parameters[0].Variadic || !lit.Parameters[1].Variadic {
+		t.Errorf("wrong variadic parameters. got=%v", lit.Parameters)
+	}
+}
+
+func TestNamedCallArguments(t *testing.T) {
+	input := `draw(1, y: 2 + 3, color: "red")`
+
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	call, ok := stmt.Expression.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.CallExpression. got=%T", stmt.Expression)
+	}
+	if len(call.Arguments) != 1 || len(call.NamedArguments) != 2 {
+		t.Fatalf("wrong arguments. positional=%d, named=%d", len(call.Arguments), len(call.NamedArguments))
+	}
+	testLiteralExpression(t, call.Arguments[0], 1)
+	testIdentifier(t, call.NamedArguments[0].Name, "y")
+	testInfixExpression(t, call.NamedArguments[0].Value, 2, "+", 3)
+	testIdentifier(t, call.NamedArguments[1].Name, "color")
+	if call.String() != "draw(1,y: (2 + 3),color: red)" {
+		t.Errorf("call.String() wrong. got=%q", call.String())
+	}
+}
+
+func TestDocComments(t *testing.T) {
+	input := `// Point is a place on the grid
+struct Point { x:int, y:int }
+
+// area of a square
+// with side s
+public fn area(s:int):int { return s * s; }
+
+/* the origin */
+var origin = Point{};
+
+fn undocumented() {}`
+
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	expected := []string{"Point is a place on the grid", "area of a square\nwith side s", "the origin", ""}
+	for i, stmt := range program.Statements {
+		var doc string
+		switch stmt := stmt.(type) {
+		case *ast.StructStatement:
+			doc = stmt.Doc
+		case *ast.FunctionStatement:
+			doc = stmt.Doc
+		case *ast.VarStatement:
+			doc = stmt.Doc
+		}
+		if doc != expected[i] {
+			t.Errorf("doc of statement %d wrong. expected=%q, got=%q", i, expected[i], doc)
+		}
+	}
+}
+
+func TestModuleStructLiteralParsing(t *testing.T) {
+	input := `geo.Point{x: 1}`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	lit, ok := stmt.Expression.(*ast.StructLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.StructLiteral. got=%T", stmt.Expression)
+	}
+
+	testIdentifier(t, lit.Module, "geo")
+	testIdentifier(t, lit.Name, "Point")
+	if lit.String() != "geo.Point{x: 1}" {
+		t.Errorf("lit.String() wrong. got=%q", lit.String())
+	}
+}
+
+func TestStructParsingErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"struct Point { x:int, x:int }", "1:23: duplicate field x in struct Point"},
+		{"Point{x: 1, x: 2}", "1:13: duplicate field x in Point literal"},
+		{"5{x: 1}", "1:1: expected struct name before '{', got 5"},
+		{"a.b.c{x: 1}", "1:1: expected struct name before '{', got a.b.c"},
+		{"interface Shape { area():int, area():int }", "1:31: duplicate method area in interface Shape"},
+		{"interface Shape { 5 }", "1:19: expected method name in interface Shape, but got 'INT' instead"},
+		{"private 5;", "1:9: expected a declaration after private, got '5' instead"},
+		{"protected var x = 1;", "1:11: protected can only be used on struct fields and methods, got 'var'"},
+		{"protected fn f() {}", "1:14: protected can only be used on struct fields and methods, f is a function"},
+		{"try { x; } var y = 1;", "1:12: expected catch or finally after try, got 'VAR' instead"},
+		{"try { x; } catch e { }", "1:18: expected next token to be '{', but got 'IDENT' instead"},
+		{"fn f(x:int = 1, y:int) {}", "1:17: parameter y needs a default value, it follows a parameter with one"},
+		{"fn f(...x:int, y:int) {}", "1:16: parameter y follows the variadic parameter x"},
+		{"fn f(...x:int = 1) {}", "1:9: variadic parameter x cannot have a default value"},
+		{"f(x: 1, 2)", "1:9: positional argument follows named argument x"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q", tt.input)
+			continue
+		}
+		if p.Errors()[0] != tt.expectedError {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expectedError, p.Errors()[0])
+		}
+	}
+}
+
+func TestErrorPositions(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"var x = 5;\nvar = 10;", "main.slang:2:5: expected next token to be 'IDENT', but got '=' instead"},
+		{"fn f(a:int) {\n\treturn a +;\n}", "main.slang:2:12: no prefix parser function found for ;"},
+		{"var x = 1;\n\n  5 = x;", "main.slang:3:3: invalid assignment target: 5"},
+		{"var x = 1;\n  p.move(1) = x;", "main.slang:2:3: invalid assignment target: p.move(1)"},
+		{"var x = 1;\n  a[0] + 1 = x;", "main.slang:2:3: invalid assignment target: ((a[0]) + 1)"},
+		{"var x = 1; // one\n/* two", "main.slang:2:1: unterminated block comment"},
+		{"var x = \"one;\nvar y = 2;", "main.slang:1:9: unterminated string literal"},
+		{"var x = 1 @ 2;", "main.slang:1:11: illegal character '@'"},
+		{"var x = \"a ${1 2} b\";", "main.slang:1:16: expected '}' after the embedded expression, got '2' instead"},
+		{"match (x) { 1 + 2 => 3 }", "main.slang:1:15: expected next token to be '=>', but got '+' instead"},
+		{"match (x) { 1 => 2 3 }", "main.slang:1:20: expected ',' or '}' after the match arm, got '3' instead"},
+		{"match (x) { x.y => 3 }", "main.slang:1:14: expected next token to be '=>', but got '.' instead"},
+		{"match (x) { (1) => 3 }", "main.slang:1:13: expected a pattern, got '(' instead"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.NewFile("main.slang", tt.input))
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q", tt.input)
+			continue
+		}
+		if p.Errors()[0] != tt.expectedError {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expectedError, p.Errors()[0])
+		}
+	}
+}
+
+func TestParseErrorRecovery(t *testing.T) {
+	input := `var x = 5;
+var = 10;
+fn f(a:int) {
+	return a +;
+	var y = ;
+	return a;
+}
+struct P { 5 }
+if (x { x; }
+var w = 3;;
+var z
+`
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+
+	expected := []string{
+		"2:5: expected next token to be 'IDENT', but got '=' instead",
+		"4:12: no prefix parser function found for ;",
+		"5:10: no prefix parser function found for ;",
+		"8:12: expected field name in struct P, but got 'INT' instead",
+		"9:10: expected next token to be ':', but got ';' instead",
+		"10:11: compiling error: expected 1 semicolon ';' ",
+		"12:1: expected next token to be '=', but got 'EOF' instead",
+	}
+	errors := p.Errors()
+	if len(errors) != len(expected) {
+		t.Fatalf("wrong number of errors. expected=%q, got=%q", expected, errors)
+	}
+	for i, msg := range expected {
+		if errors[i] != msg {
+			t.Errorf("wrong error %d. expected=%q, got=%q", i, msg, errors[i])
+		}
+	}
+
+	var fn *ast.FunctionStatement
+	for _, stmt := range program.Statements {
+		if stmt, ok := stmt.(*ast.FunctionStatement); ok && stmt != nil {
+			fn = stmt
+		}
+	}
+	if fn == nil {
+		t.Fatalf("the function statement was not parsed")
+	}
+	last := fn.Block.Statements[len(fn.Block.Statements)-1]
+	if last.String() != "returna;" {
+		t.Errorf("the function body was not recovered. got=%q", last.String())
+	}
+}
+
+func TestParseErrorValues(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedCode     ErrorCode
+		expectedExpected token.TokenType
+		expectedGot      token.TokenType
+		expectedLine     int
+		expectedColumn   int
+	}{
+		{"var = 5;", UnexpectedToken, token.IDENT, token.ASSIGN, 1, 5},
+		{"\n  5 + ;", MissingExpression, "", token.SEMICOLON, 2, 7},
+		{"struct P { x:int, x:int }", Duplicate, "", "", 1, 19},
+		{"fn f(a) {}", MissingType, "", "", 1, 6},
+		{"private 5;", InvalidModifier, "", token.INT, 1, 9},
+		{"5 = 1;", InvalidAssignTarget, "", "", 1, 1},
+		{"fn f() {\n\t#add \"lib\"\n}", MisplacedDirective, "", "", 2, 2},
+		{"fn f() { import \"math\"; }", MisplacedDirective, "", "", 1, 10},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.ParseErrors()
+		if len(errors) == 0 {
+			t.Errorf("expected parser errors for %q", tt.input)
+			continue
+		}
+		err := errors[0]
+		if err.Code != tt.expectedCode || err.Expected != tt.expectedExpected || err.Got != tt.expectedGot {
+			t.Errorf("wrong error for %q. expected code=%s expected=%q got=%q, got %+v",
+				tt.input, tt.expectedCode, tt.expectedExpected, tt.expectedGot, err)
+		}
+		if err.Pos.Line != tt.expectedLine || err.Pos.Column != tt.expectedColumn {
+			t.Errorf("wrong position for %q. expected=%d:%d, got=%s", tt.input, tt.expectedLine, tt.expectedColumn, err.Pos)
+		}
+	}
+}
+
+func TestUnterminatedStatements(t *testing.T) {
+	tests := []string{"var x = 5", "return 5", "var x = ", "return"}
+
+	for _, input := range tests {
+		done := make(chan bool)
+		go func() {
+			New(lexer.New(input)).ParseProgram()
+			done <- true
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("parser does not stop on %q", input)
+		}
+	}
+}
@@ -2,9 +2,9 @@ package repl
 
 import (
 	"Goslang/evaluator"
-	"Goslang/lexer"
+	"Goslang/loader"
 	"Goslang/object"
-	"Goslang/parser"
+	"Goslang/typecheck"
 	"bufio"
 	"fmt"
 	"io"
@@ -12,9 +12,15 @@ import (
 
 const PROMPT = ">> "
 
+// REPL_FILE is the file name of the lines typed in the REPL, their includes are
+// resolved relative to the working directory.
+const REPL_FILE = "repl"
+
 func Start(in io.Reader, out io.Writer) {
 	scanner := bufio.NewScanner(in)
 	env := object.NewEnvironment()
+	files := loader.New()
+	checker := typecheck.New()
 	for {
 		fmt.Printf(PROMPT)
 		scanned := scanner.Scan()
@@ -22,14 +28,24 @@ func Start(in io.Reader, out io.Writer) {
 			return
 		}
 		line := scanner.Text()
-		l := lexer.New(line)
-		p := parser.New(l)
-		program := p.ParseProgram()
-		if len(p.Errors()) != 0 {
-			printParserErrors(out, p.Errors())
+		program, err := files.Load(REPL_FILE, line)
+		if err != nil {
+			if loadErr, ok := err.(*loader.Error); ok && loadErr.File == REPL_FILE {
+				printParserErrors(out, loadErr.Messages)
+			} else {
+				printParserErrors(out, []string{err.Error()})
+			}
+			continue
+		}
+		if typeErrors := checker.Check(program); len(typeErrors) != 0 {
+			printParserErrors(out, typeErrors)
 			continue
 		}
 		evaluated := evaluator.Eval(program, env)
+		if err, ok := evaluated.(*object.Error); ok {
+			io.WriteString(out, err.Traceback()+"\n")
+			continue
+		}
 		if evaluated != nil {
 			if arr, ok := evaluated.(*object.PrintObject); ok {
 				for _, element := range arr.Elements {
@@ -1,10 +1,34 @@
 package token
 
+import "fmt"
+
 type TokenType string
 
 type Token struct {
 	Type    TokenType
 	Literal string
+	Pos     Position //where the token starts
+	Doc     string   //the comment right above the token, kept for tooling
+}
+
+// Position is a place in the source, lines and columns start at 1.
+type Position struct {
+	File   string //empty when the source was not read from a file
+	Line   int
+	Column int
+}
+
+// String formats the position as file:line:column, or line:column without a file.
+func (p Position) String() string {
+	if p.File == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// IsValid reports whether the position was set, the zero Position is not a place in the source.
+func (p Position) IsValid() bool {
+	return p.Line > 0
 }
 
 const (
@@ -14,8 +38,14 @@ const (
 	//Identifiers + literals
 	IDENT  = "IDENT"
 	INT    = "INT"
+	FLOAT  = "FLOAT"
 	STRING = "STRING"
 
+	//A string with interpolations is lexed as its TEMPLATE parts, each followed by the
+	//tokens of an embedded expression, and the TEMPLATE_END part after the last one
+	TEMPLATE     = "TEMPLATE"
+	TEMPLATE_END = "TEMPLATE_END"
+
 	//Operators
 	ASSIGN   = "="
 	PLUS     = "+"
@@ -29,8 +59,23 @@ const (
 	EQ     = "=="
 	NOT_EQ = "!="
 
-	LT = "<"
-	GT = ">"
+	//Assignment operators
+	PLUS_ASSIGN     = "+="
+	MINUS_ASSIGN    = "-="
+	ASTERISK_ASSIGN = "*="
+	SLASH_ASSIGN    = "/="
+	MODULUS_ASSIGN  = "%="
+	INCREMENT       = "++"
+	DECREMENT       = "--"
+
+	LT    = "<"
+	GT    = ">"
+	LT_EQ = "<="
+	GT_EQ = ">="
+
+	//LOGICAL OPERATORS
+	AND = "&&"
+	OR  = "||"
 
 	//BITWISE OPERATORS
 	BITAND = "&"
@@ -38,10 +83,15 @@ const (
 	BITNOT = "~"
 	BITXOR = "#"
 
+	INCLUDE = "#add"
+
 	//Delimiters
 	COMMA     = ","
 	SEMICOLON = ";"
 	COLON     = ":"
+	DOT       = "."
+	ELLIPSIS  = "..."
+	ARROW     = "=>"
 
 	LPAREN   = "("
 	RPAREN   = ")"
@@ -59,17 +109,61 @@ const (
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
 	PRINT    = "PRINT"
+	WHILE    = "WHILE"
+	DO       = "DO"
+	BREAK    = "BREAK"
+	CONTINUE = "CONTINUE"
+	FOR      = "FOR"
+	FOREACH  = "FOREACH"
+	IN       = "IN"
+	STRUCT   = "STRUCT"
+
+	INTERFACE = "INTERFACE"
+	PUBLIC    = "PUBLIC"
+	PRIVATE   = "PRIVATE"
+	PROTECTED = "PROTECTED"
+	IMPORT    = "IMPORT"
+	AS        = "AS"
+
+	THROW   = "THROW"
+	TRY     = "TRY"
+	CATCH   = "CATCH"
+	FINALLY = "FINALLY"
+
+	MATCH = "MATCH"
 )
 
 var keywords = map[string]TokenType{
-	"fn":     FUNCTION,
-	"var":    VAR,
-	"truth":  TRUTH,
-	"lie":    LIE,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
-	"print":  PRINT,
+	"fn":       FUNCTION,
+	"var":      VAR,
+	"truth":    TRUTH,
+	"lie":      LIE,
+	"if":       IF,
+	"else":     ELSE,
+	"return":   RETURN,
+	"print":    PRINT,
+	"while":    WHILE,
+	"do":       DO,
+	"break":    BREAK,
+	"continue": CONTINUE,
+	"for":      FOR,
+	"foreach":  FOREACH,
+	"in":       IN,
+	"struct":   STRUCT,
+
+	"interface": INTERFACE,
+	"public":    PUBLIC,
+	"private":   PRIVATE,
+	"protected": PROTECTED,
+	"import":    IMPORT,
+	"as":        AS,
+
+	"throw":   THROW,
+	"try":     TRY,
+	"catch":   CATCH,
+	"finally": FINALLY,
+
+	"match": MATCH,
 }
 
 func LookupIdent(ident string) TokenType {
@@ -79,6 +173,8 @@ func LookupIdent(ident string) TokenType {
 		switch ident {
 		case "int":
 			return INT
+		case "float":
+			return FLOAT
 		case "string":
 			return STRING
 		default:
@@ -0,0 +1,1084 @@
+// Package typecheck checks the types of a program before it is evaluated. It uses the
+// type annotations of functions and struct fields and the types it can infer from
+// literals, operators and calls. Expressions whose type cannot be known before the
+// program runs are accepted, the evaluator still checks them at runtime.
+package typecheck
+
+import (
+	"Goslang/ast"
+	"Goslang/object"
+	"Goslang/token"
+	"fmt"
+	"strings"
+)
+
+// Type is the type of an expression, an object type, e.g.: INTEGER, or the name of a struct or interface.
+type Type string
+
+// Unknown is the type of the expressions that can only be known at runtime.
+const Unknown Type = ""
+
+const (
+	Integer  = Type(object.INTEGER_OBJ)
+	Float    = Type(object.FLOAT_OBJ)
+	String   = Type(object.STRING_OBJ)
+	Boolean  = Type(object.BOOLEAN_OBJ)
+	Array    = Type(object.ARRAY_OBJ)
+	Hash     = Type(object.HASH_OBJ)
+	Function = Type(object.FUNCTION_OBJ)
+)
+
+// annotations are the type names of the language that can be written in annotations
+var annotations = map[string]Type{
+	"int":    Integer,
+	"float":  Float,
+	"string": String,
+	"bool":   Boolean,
+	"array":  Array,
+	"hash":   Hash,
+	"fn":     Function,
+}
+
+// builtinResults are the types returned by the builtin functions that always return the same type
+var builtinResults = map[string]Type{
+	"len":     Integer,
+	"ord":     Integer,
+	"chr":     String,
+	"chars":   Array,
+	"Atoi":    Integer,
+	"randInt": Integer,
+	"push":    Array,
+	"sort":    Array,
+	"keys":    Array,
+	"values":  Array,
+	"has":     Boolean,
+	"delete":  Hash,
+}
+
+type signature struct {
+	name       string
+	parameters []*ast.FunctionParameter
+	returnType *ast.TypeAnnotation
+}
+
+type structInfo struct {
+	node    *ast.StructStatement
+	methods map[string]*signature
+	access  map[string]string
+}
+
+// symbol is a declared name, sig is set when the name is known to hold a function
+type symbol struct {
+	typ Type
+	sig *signature
+}
+
+type scope struct {
+	names map[string]*symbol
+	outer *scope
+}
+
+func newScope(outer *scope) *scope {
+	return &scope{names: map[string]*symbol{}, outer: outer}
+}
+
+func (s *scope) lookup(name string) (*symbol, bool) {
+	if sym, ok := s.names[name]; ok {
+		return sym, true
+	}
+	if s.outer != nil {
+		return s.outer.lookup(name)
+	}
+	return nil, false
+}
+
+// Checker keeps the declarations of the programs it checked, so the lines of the REPL
+// can use what the previous lines declared.
+type Checker struct {
+	globals    *scope
+	structs    map[string]*structInfo
+	interfaces map[string]*ast.InterfaceStatement
+	included   map[*ast.Program]bool
+	assigned   map[string]bool //the names assigned or declared again anywhere in the checked files
+	function   *signature      //the function whose body is checked, nil at the top level
+	receiver   string          //the struct of the method whose body is checked
+	pos        token.Position  //the position of the innermost node being checked
+	caught     bool            //the errors of the code being checked are caught at run time by a try with a catch
+	errors     []string
+}
+
+func New() *Checker {
+	return &Checker{
+		globals:    newScope(nil),
+		structs:    map[string]*structInfo{},
+		interfaces: map[string]*ast.InterfaceStatement{},
+		included:   map[*ast.Program]bool{},
+		assigned:   map[string]bool{},
+	}
+}
+
+// Check reports every type mismatch of the program, it returns no errors if the types are correct.
+func (c *Checker) Check(program *ast.Program) []string {
+	c.errors = []string{}
+	c.collectAssigned(program, map[string]bool{})
+	c.declare(program.Statements, c.globals, false)
+	for _, statement := range program.Statements {
+		c.checkStatement(statement, c.globals)
+	}
+	return c.errors
+}
+
+// enter makes node the node being checked, the returned function goes back to the previous one.
+func (c *Checker) enter(node ast.Node) func() {
+	previous := c.pos
+	if node != nil {
+		c.pos = node.Pos()
+	}
+	return func() { c.pos = previous }
+}
+
+// errorf adds an error for the node being checked.
+func (c *Checker) errorf(format string, a ...interface{}) {
+	c.errorAt(c.pos, format, a...)
+}
+
+func (c *Checker) errorAt(pos token.Position, format string, a ...interface{}) {
+	if c.caught {
+		return
+	}
+	c.errors = append(c.errors, pos.String()+": "+fmt.Sprintf(format, a...))
+}
+
+// declare registers the structs, interfaces and functions of a file before its statements
+// are checked, functions can then be called before their declaration. Only the public
+// declarations of included files are registered.
+func (c *Checker) declare(statements []ast.Statement, s *scope, included bool) {
+	for _, statement := range statements {
+		switch statement := statement.(type) {
+		case *ast.StructStatement:
+			if !included || statement.Access != "private" {
+				c.declareStruct(statement)
+			}
+		case *ast.InterfaceStatement:
+			if !included || statement.Access != "private" {
+				c.interfaces[statement.Name.Value] = statement
+			}
+		case *ast.FunctionStatement:
+			if statement.Receiver == nil && (!included || statement.Access != "private") {
+				s.names[statement.Name.Value] = c.variable(statement.Name.Value, Function, functionSignature(statement))
+			}
+		case *ast.VarStatement:
+			if included && statement.Access != "private" {
+				s.names[statement.Name.Value] = &symbol{typ: Unknown}
+			}
+		case *ast.IncludeStatement:
+			c.include(statement, s)
+		}
+	}
+
+	for _, statement := range statements {
+		if fn, ok := statement.(*ast.FunctionStatement); ok && fn.Receiver != nil {
+			c.declareMethod(fn)
+		}
+	}
+}
+
+// variable returns the symbol of a name bound to a value of type typ, sig is set when the
+// value is a known function. A name that is assigned somewhere can hold any value, the
+// evaluator lets it change type, so its type is unknown.
+func (c *Checker) variable(name string, typ Type, sig *signature) *symbol {
+	if c.assigned[name] {
+		return &symbol{typ: Unknown}
+	}
+	return &symbol{typ: typ, sig: sig}
+}
+
+// collectAssigned records the names that node assigns, or declares more than once, in
+// c.assigned. declared holds the names already declared in the file.
+func (c *Checker) collectAssigned(node ast.Node, declared map[string]bool) {
+	declare := func(name *ast.Identifier) {
+		if name == nil {
+			return
+		}
+		if declared[name.Value] {
+			c.assigned[name.Value] = true
+		}
+		declared[name.Value] = true
+	}
+	walk := func(nodes ...ast.Node) {
+		for _, node := range nodes {
+			c.collectAssigned(node, declared)
+		}
+	}
+	functionBody := func(params []*ast.FunctionParameter, block *ast.BlockStatement) {
+		for _, param := range params {
+			if param.Default != nil {
+				walk(param.Default)
+			}
+		}
+		walk(block)
+	}
+
+	switch node := node.(type) {
+	case *ast.Program:
+		for _, statement := range node.Statements {
+			walk(statement)
+		}
+	case *ast.BlockStatement:
+		if node == nil {
+			return
+		}
+		for _, statement := range node.Statements {
+			walk(statement)
+		}
+	case *ast.VarStatement:
+		declare(node.Name)
+		walk(node.Value)
+	case *ast.FunctionStatement:
+		if node.Receiver == nil {
+			declare(node.Name)
+		}
+		functionBody(node.Parameters, node.Block)
+	case *ast.FunctionLiteral:
+		functionBody(node.Parameters, node.Block)
+	case *ast.AssignExpression:
+		if name, ok := node.Target.(*ast.Identifier); ok {
+			c.assigned[name.Value] = true
+		}
+		walk(node.Target, node.Value)
+	case *ast.IncrementExpression:
+		//++ and -- keep the type of a number and fail on anything else
+		walk(node.Target)
+	case *ast.ExpressionStatement:
+		walk(node.Expression)
+	case *ast.ReturnStatement:
+		walk(node.ReturnValue)
+	case *ast.ThrowStatement:
+		walk(node.Value)
+	case *ast.PrefixExpression:
+		walk(node.Right)
+	case *ast.InfixExpression:
+		walk(node.Left, node.Right)
+	case *ast.IfExpression:
+		walk(node.Condition, node.Consequence, node.Alternative)
+	case *ast.MatchExpression:
+		walk(node.Value)
+		for _, arm := range node.Arms {
+			if arm.Guard != nil {
+				walk(arm.Guard)
+			}
+			walk(arm.Body)
+		}
+	case *ast.CallExpression:
+		walk(node.Function)
+		for _, arg := range node.Arguments {
+			walk(arg)
+		}
+		for _, arg := range node.NamedArguments {
+			walk(arg.Value)
+		}
+	case *ast.ArrayLiteral:
+		for _, element := range node.Elements {
+			walk(element)
+		}
+	case *ast.HashLiteral:
+		for _, key := range node.Keys {
+			walk(key, node.Pairs[key])
+		}
+	case *ast.TemplateLiteral:
+		for _, part := range node.Parts {
+			walk(part)
+		}
+	case *ast.IndexExpression:
+		walk(node.Left, node.Index)
+	case *ast.MemberExpression:
+		walk(node.Object)
+	case *ast.StructLiteral:
+		for _, value := range node.Values {
+			walk(value)
+		}
+	case *ast.WhileStatement:
+		walk(node.Condition, node.Block)
+	case *ast.DoWhileStatement:
+		walk(node.Block, node.Condition)
+	case *ast.ForStatement:
+		if node.Init != nil {
+			walk(node.Init)
+		}
+		if node.Condition != nil {
+			walk(node.Condition)
+		}
+		if node.Update != nil {
+			walk(node.Update)
+		}
+		walk(node.Block)
+	case *ast.ForeachStatement:
+		walk(node.Collection, node.Block)
+	case *ast.TryStatement:
+		walk(node.Block, node.CatchBlock, node.FinallyBlock)
+	}
+}
+
+func (c *Checker) include(node *ast.IncludeStatement, s *scope) {
+	if node.Program == nil || c.included[node.Program] {
+		return
+	}
+	c.included[node.Program] = true
+	c.collectAssigned(node.Program, map[string]bool{})
+	c.declare(node.Program.Statements, s, true)
+}
+
+func (c *Checker) declareStruct(node *ast.StructStatement) {
+	info := &structInfo{node: node, methods: map[string]*signature{}, access: map[string]string{}}
+	for _, field := range node.Fields {
+		if field.Access != "" {
+			info.access[field.Name.Value] = field.Access
+		}
+	}
+	c.structs[node.Name.Value] = info
+}
+
+func (c *Checker) declareMethod(node *ast.FunctionStatement) {
+	info, ok := c.structs[node.Receiver.Value]
+	if !ok {
+		return
+	}
+	info.methods[node.Name.Value] = functionSignature(node)
+	if node.Access != "" {
+		info.access[node.Name.Value] = node.Access
+	}
+}
+
+func functionSignature(node *ast.FunctionStatement) *signature {
+	return &signature{name: node.Name.Value, parameters: node.Parameters, returnType: node.ReturnType}
+}
+
+func (c *Checker) checkStatement(statement ast.Statement, s *scope) {
+	defer c.enter(statement)()
+	switch node := statement.(type) {
+
+	case *ast.VarStatement:
+		if lit, ok := node.Value.(*ast.FunctionLiteral); ok {
+			c.inferFunctionLiteral(lit, s)
+			s.names[node.Name.Value] = c.variable(node.Name.Value, Function, literalSignature(lit))
+			return
+		}
+		s.names[node.Name.Value] = c.variable(node.Name.Value, c.infer(node.Value, s), nil)
+
+	case *ast.ExpressionStatement:
+		c.infer(node.Expression, s)
+
+	case *ast.ReturnStatement:
+		returned := c.infer(node.ReturnValue, s)
+		if c.function != nil && c.function.returnType != nil {
+			if mismatch := c.checkAnnotation(c.function.returnType, returned); mismatch != "" {
+				c.errorf("return value of %s: %s", c.function.name, mismatch)
+			}
+		}
+
+	case *ast.FunctionStatement:
+		sig := functionSignature(node)
+		if node.Receiver != nil {
+			receiver := ""
+			if _, ok := c.structs[node.Receiver.Value]; ok {
+				c.declareMethod(node)
+				receiver = node.Receiver.Value
+			}
+			c.checkFunction(sig, node.Block, s, receiver)
+			return
+		}
+		s.names[node.Name.Value] = c.variable(node.Name.Value, Function, sig)
+		c.checkFunction(sig, node.Block, s, c.receiver)
+
+	case *ast.StructStatement:
+		if _, ok := c.structs[node.Name.Value]; !ok {
+			c.declareStruct(node)
+		}
+		for _, field := range node.Fields {
+			if !field.Embedded && c.resolve(field.Type) == Unknown {
+				c.errorAt(field.Name.Pos(), "field %s of %s: unknown type %s", field.Name.Value, node.Name.Value, field.Type.Value)
+			}
+		}
+
+	case *ast.InterfaceStatement:
+		c.interfaces[node.Name.Value] = node
+
+	case *ast.IncludeStatement:
+		c.include(node, s)
+
+	case *ast.ImportStatement:
+		s.names[node.Alias.Value] = &symbol{typ: Unknown}
+
+	case *ast.BlockStatement:
+		c.checkBlock(node, s)
+
+	case *ast.WhileStatement:
+		c.infer(node.Condition, s)
+		c.checkBlock(node.Block, s)
+
+	case *ast.DoWhileStatement:
+		c.checkBlock(node.Block, s)
+		c.infer(node.Condition, s)
+
+	case *ast.ForStatement:
+		loopScope := newScope(s)
+		if node.Init != nil {
+			c.checkStatement(node.Init, loopScope)
+		}
+		if node.Condition != nil {
+			c.infer(node.Condition, loopScope)
+		}
+		if node.Update != nil {
+			c.infer(node.Update, loopScope)
+		}
+		c.checkBlock(node.Block, loopScope)
+
+	case *ast.ThrowStatement:
+		thrown := c.infer(node.Value, s)
+		if thrown != Unknown && thrown != String {
+			c.errorf("throw expects a STRING or an Error, got %s", c.objectType(thrown))
+		}
+
+	case *ast.TryStatement:
+		//an error in a try block with a catch is handled at run time, so it is not reported
+		caught := c.caught
+		c.caught = caught || node.CatchBlock != nil
+		c.checkBlock(node.Block, s)
+		c.caught = caught
+		if node.CatchBlock != nil {
+			catchScope := newScope(s)
+			if node.CatchName != nil {
+				catchScope.names[node.CatchName.Value] = &symbol{typ: Unknown}
+			}
+			c.checkBlock(node.CatchBlock, catchScope)
+		}
+		c.checkBlock(node.FinallyBlock, s)
+
+	case *ast.ForeachStatement:
+		collection := c.infer(node.Collection, s)
+		loopScope := newScope(s)
+		element := Unknown
+		if collection == String {
+			element = String
+		}
+		loopScope.names[node.Variable.Value] = c.variable(node.Variable.Value, element, nil)
+		c.checkBlock(node.Block, loopScope)
+	}
+}
+
+// bindPattern declares the names bound by a pattern of a match arm in s, value is the type
+// of the value the pattern matches.
+func (c *Checker) bindPattern(pattern ast.Expression, value Type, s *scope) {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		if pattern.Value != "_" {
+			s.names[pattern.Value] = c.variable(pattern.Value, value, nil)
+		}
+	case *ast.ArrayLiteral:
+		for _, element := range pattern.Elements {
+			c.bindPattern(element, Unknown, s)
+		}
+	}
+}
+
+func (c *Checker) checkBlock(block *ast.BlockStatement, s *scope) {
+	if block == nil {
+		return
+	}
+	for _, statement := range block.Statements {
+		c.checkStatement(statement, s)
+	}
+}
+
+// checkFunction checks the body of a function in its own scope, receiver is the struct
+// of a method or of the method the function is declared in.
+func (c *Checker) checkFunction(sig *signature, block *ast.BlockStatement, s *scope, receiver string) {
+	//the function runs when it is called, which may be outside of the try it is declared in
+	caught := c.caught
+	c.caught = false
+	defer func() { c.caught = caught }()
+
+	fnScope := newScope(s)
+	for _, param := range sig.parameters {
+		paramType := Unknown
+		if param.Type != nil {
+			paramType = c.resolve(param.Type)
+			if paramType == Unknown {
+				c.errorAt(param.Name.Pos(), "parameter %s of %s: unknown type %s", param.Name.Value, sig.name, param.Type.Value)
+			}
+		}
+		if param.Default != nil {
+			//the default value is evaluated in the function scope, after the parameters before it
+			value := c.infer(param.Default, fnScope)
+			if paramType != Unknown {
+				if mismatch := c.checkAnnotation(param.Type, value); mismatch != "" {
+					c.errorAt(param.Default.Pos(), "default value of %s in %s: %s", param.Name.Value, sig.name, mismatch)
+				}
+			}
+		}
+		if param.Variadic {
+			paramType = Array
+		}
+		fnScope.names[param.Name.Value] = c.variable(param.Name.Value, paramType, nil)
+	}
+	if sig.returnType != nil && c.resolve(sig.returnType) == Unknown {
+		c.errorAt(sig.returnType.Pos(), "return type of %s: unknown type %s", sig.name, sig.returnType.Value)
+	}
+	if receiver != "" {
+		fnScope.names["self"] = c.variable("self", Type(receiver), nil)
+	}
+
+	function, outerReceiver := c.function, c.receiver
+	c.function, c.receiver = sig, receiver
+	c.checkBlock(block, fnScope)
+	c.function, c.receiver = function, outerReceiver
+}
+
+func (c *Checker) inferFunctionLiteral(lit *ast.FunctionLiteral, s *scope) Type {
+	c.checkFunction(literalSignature(lit), lit.Block, s, c.receiver)
+	return Function
+}
+
+func literalSignature(lit *ast.FunctionLiteral) *signature {
+	return &signature{name: "anonymous function", parameters: lit.Parameters, returnType: lit.ReturnType}
+}
+
+// resolve returns the type named by an annotation, Unknown if no such type is declared.
+func (c *Checker) resolve(t *ast.TypeAnnotation) Type {
+	if t == nil {
+		return Unknown
+	}
+	if builtin, ok := annotations[t.Value]; ok {
+		return builtin
+	}
+	if _, ok := c.structs[t.Value]; ok {
+		return Type(t.Value)
+	}
+	if _, ok := c.interfaces[t.Value]; ok {
+		return Type(t.Value)
+	}
+	return Unknown
+}
+
+// checkAnnotation compares the type of a value with the annotation it is passed to,
+// it returns a description of the mismatch or an empty string if the value is accepted.
+func (c *Checker) checkAnnotation(t *ast.TypeAnnotation, actual Type) string {
+	expected := c.resolve(t)
+	if expected == Unknown {
+		return ""
+	}
+	return c.compatible(expected, actual)
+}
+
+// compatible describes why a value of type actual cannot be used as expected,
+// it returns an empty string if it can.
+func (c *Checker) compatible(expected Type, actual Type) string {
+	if actual == Unknown || expected == Unknown || actual == expected {
+		return ""
+	}
+	if expected == Float && actual == Integer {
+		return ""
+	}
+	if _, ok := c.interfaces[string(actual)]; ok {
+		//the value implementing an interface is only known at runtime
+		return ""
+	}
+
+	if iface, ok := c.interfaces[string(expected)]; ok {
+		missing := iface.Methods
+		if _, ok := c.structs[string(actual)]; ok {
+			missing = c.missingMethods(string(actual), iface)
+		}
+		if len(missing) == 0 {
+			return ""
+		}
+		signatures := []string{}
+		for _, method := range missing {
+			signatures = append(signatures, method.String())
+		}
+		return fmt.Sprintf("%s does not implement %s (missing methods: %s)",
+			actual, iface.Name.Value, strings.Join(signatures, ", "))
+	}
+
+	return fmt.Sprintf("expected %s, got %s", expected, actual)
+}
+
+// missingMethods returns the methods of the interface the struct does not declare with a matching signature.
+func (c *Checker) missingMethods(structName string, iface *ast.InterfaceStatement) []*ast.InterfaceMethod {
+	missing := []*ast.InterfaceMethod{}
+	for _, method := range iface.Methods {
+		_, _, sig := c.findMember(structName, method.Name.Value)
+		if sig == nil || !sameSignature(method, sig) {
+			missing = append(missing, method)
+		}
+	}
+	return missing
+}
+
+func sameSignature(method *ast.InterfaceMethod, sig *signature) bool {
+	if len(method.Parameters) != len(sig.parameters) {
+		return false
+	}
+	for i, param := range method.Parameters {
+		if annotationName(param.Type) != annotationName(sig.parameters[i].Type) || param.Variadic != sig.parameters[i].Variadic {
+			return false
+		}
+	}
+	return annotationName(method.ReturnType) == annotationName(sig.returnType)
+}
+
+func annotationName(t *ast.TypeAnnotation) string {
+	if t == nil {
+		return ""
+	}
+	return t.Value
+}
+
+// findMember finds the field or method name of a struct or of the structs it embeds,
+// owner is the struct that declares it.
+func (c *Checker) findMember(structName string, name string) (owner string, field *ast.StructField, method *signature) {
+	info, ok := c.structs[structName]
+	if !ok {
+		return "", nil, nil
+	}
+	for _, f := range info.node.Fields {
+		if f.Name.Value == name {
+			return structName, f, nil
+		}
+	}
+	if sig, ok := info.methods[name]; ok {
+		return structName, nil, sig
+	}
+	for _, f := range info.node.Fields {
+		if f.Embedded {
+			if owner, field, method := c.findMember(f.Type.Value, name); owner != "" {
+				return owner, field, method
+			}
+		}
+	}
+	return "", nil, nil
+}
+
+// embeds reports whether the struct embeds other, directly or through another embedded struct.
+func (c *Checker) embeds(structName string, other string) bool {
+	info, ok := c.structs[structName]
+	if !ok {
+		return false
+	}
+	for _, f := range info.node.Fields {
+		if f.Embedded && (f.Type.Value == other || c.embeds(f.Type.Value, other)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Checker) checkAccess(owner string, name string) {
+	access := c.structs[owner].access[name]
+	if access != "private" && access != "protected" {
+		return
+	}
+	if c.receiver == owner || (access == "protected" && c.embeds(c.receiver, owner)) {
+		return
+	}
+	c.errorf("cannot access %s member %s of struct %s", access, name, owner)
+}
+
+// objectType is the name of a type in operator errors, struct values are STRUCT objects.
+func (c *Checker) objectType(t Type) string {
+	if _, ok := c.structs[string(t)]; ok {
+		return string(object.STRUCT_OBJ)
+	}
+	return string(t)
+}
+
+func (c *Checker) infer(node ast.Expression, s *scope) Type {
+	defer c.enter(node)()
+	switch node := node.(type) {
+
+	case *ast.IntegerLiteral:
+		return Integer
+
+	case *ast.FloatLiteral:
+		return Float
+
+	case *ast.StringLiteral:
+		return String
+
+	case *ast.TemplateLiteral:
+		for _, part := range node.Parts {
+			c.infer(part, s)
+		}
+		return String
+
+	case *ast.Boolean:
+		return Boolean
+
+	case *ast.ArrayLiteral:
+		for _, element := range node.Elements {
+			c.infer(element, s)
+		}
+		return Array
+
+	case *ast.HashLiteral:
+		for _, key := range node.Keys {
+			c.infer(key, s)
+			c.infer(node.Pairs[key], s)
+		}
+		return Hash
+
+	case *ast.Identifier:
+		if sym, ok := s.lookup(node.Value); ok {
+			return sym.typ
+		}
+		return Unknown
+
+	case *ast.PrefixExpression:
+		return c.inferPrefix(node, s)
+
+	case *ast.InfixExpression:
+		left := c.infer(node.Left, s)
+		right := c.infer(node.Right, s)
+		return c.inferInfix(node.Operator, left, right)
+
+	case *ast.IfExpression:
+		c.infer(node.Condition, s)
+		c.checkBlock(node.Consequence, s)
+		c.checkBlock(node.Alternative, s)
+		return Unknown
+
+	case *ast.MatchExpression:
+		value := c.infer(node.Value, s)
+		for _, arm := range node.Arms {
+			armScope := newScope(s)
+			for _, pattern := range arm.Patterns {
+				c.bindPattern(pattern, value, armScope)
+			}
+			if arm.Guard != nil {
+				c.infer(arm.Guard, armScope)
+			}
+			if block, ok := arm.Body.(*ast.BlockStatement); ok {
+				c.checkBlock(block, armScope)
+			} else {
+				c.infer(arm.Body.(ast.Expression), armScope)
+			}
+		}
+		return Unknown
+
+	case *ast.FunctionLiteral:
+		return c.inferFunctionLiteral(node, s)
+
+	case *ast.FunctionStatement:
+		c.checkStatement(node, s)
+		return Function
+
+	case *ast.CallExpression:
+		return c.inferCall(node, s)
+
+	case *ast.IndexExpression:
+		left := c.infer(node.Left, s)
+		index := c.infer(node.Index, s)
+		if left == String && index == Integer {
+			return String
+		}
+		return Unknown
+
+	case *ast.AssignExpression:
+		return c.inferAssign(node, s)
+
+	case *ast.IncrementExpression:
+		target := c.infer(node.Target, s)
+		if target != Unknown && target != Integer && target != Float {
+			c.errorf("unknown operator: %s%s", c.objectType(target), node.Operator)
+			return Unknown
+		}
+		return target
+
+	case *ast.StructLiteral:
+		return c.inferStructLiteral(node, s)
+
+	case *ast.MemberExpression:
+		return c.inferMember(node, s)
+	}
+
+	return Unknown
+}
+
+func (c *Checker) inferPrefix(node *ast.PrefixExpression, s *scope) Type {
+	right := c.infer(node.Right, s)
+	switch node.Operator {
+	case "!":
+		return Boolean
+	case "-":
+		if right == Unknown || right == Integer || right == Float {
+			return right
+		}
+	case "~":
+		if right == Unknown || right == Integer {
+			return Integer
+		}
+	}
+	c.errorf("unknown operator: %s%s", node.Operator, c.objectType(right))
+	return Unknown
+}
+
+// inferInfix follows the rules of the evaluator for the infix operators.
+func (c *Checker) inferInfix(operator string, left Type, right Type) Type {
+	comparison := operator == "<" || operator == ">" || operator == "<=" || operator == ">=" ||
+		operator == "==" || operator == "!="
+	bitwise := operator == "&" || operator == "|" || operator == "#"
+	numeric := func(t Type) bool { return t == Integer || t == Float }
+
+	switch {
+	case operator == "&&" || operator == "||":
+		return Boolean
+
+	case left == Unknown || right == Unknown:
+		if comparison {
+			return Boolean
+		}
+		return Unknown
+
+	case left == Integer && right == Integer:
+		if comparison {
+			return Boolean
+		}
+		return Integer
+
+	case numeric(left) && numeric(right):
+		if comparison {
+			return Boolean
+		}
+		if !bitwise {
+			return Float
+		}
+
+	case left == String && right == String:
+		if comparison {
+			return Boolean
+		}
+		if operator == "+" {
+			return String
+		}
+
+	case operator == "==" || operator == "!=":
+		return Boolean
+
+	case left != right:
+		c.errorf("type mismatch: %s %s %s", c.objectType(left), operator, c.objectType(right))
+		return Unknown
+	}
+
+	c.errorf("unknown operator: %s %s %s", c.objectType(left), operator, c.objectType(right))
+	return Unknown
+}
+
+func (c *Checker) inferCall(node *ast.CallExpression, s *scope) Type {
+	args := []Type{}
+	for _, arg := range node.Arguments {
+		args = append(args, c.infer(arg, s))
+	}
+	named := []Type{}
+	for _, arg := range node.NamedArguments {
+		named = append(named, c.infer(arg.Value, s))
+	}
+
+	switch callee := node.Function.(type) {
+	case *ast.Identifier:
+		sym, ok := s.lookup(callee.Value)
+		if !ok {
+			return builtinResults[callee.Value]
+		}
+		if sym.sig != nil {
+			return c.checkCall(sym.sig, node, args, named)
+		}
+		if sym.typ != Unknown && sym.typ != Function {
+			c.errorf("not a function: %s", c.objectType(sym.typ))
+		}
+		return Unknown
+
+	case *ast.FunctionLiteral:
+		c.inferFunctionLiteral(callee, s)
+		return c.checkCall(literalSignature(callee), node, args, named)
+
+	case *ast.MemberExpression:
+		receiver := c.infer(callee.Object, s)
+		if _, ok := c.structs[string(receiver)]; ok {
+			if owner, _, method := c.findMember(string(receiver), callee.Property.Value); method != nil {
+				c.checkAccess(owner, callee.Property.Value)
+				return c.checkCall(method, node, args, named)
+			}
+		}
+		if iface, ok := c.interfaces[string(receiver)]; ok {
+			for _, method := range iface.Methods {
+				if method.Name.Value == callee.Property.Value {
+					sig := &signature{name: method.Name.Value, parameters: method.Parameters, returnType: method.ReturnType}
+					return c.checkCall(sig, node, args, named)
+				}
+			}
+			return Unknown
+		}
+		c.inferMemberOf(receiver, callee.Property.Value)
+		return Unknown
+	}
+
+	callee := c.infer(node.Function, s)
+	if callee != Unknown && callee != Function {
+		c.errorf("not a function: %s", c.objectType(callee))
+	}
+	return Unknown
+}
+
+// checkCall checks the arguments of the call node and returns the type the function returns,
+// named holds the types of the named arguments of the node.
+func (c *Checker) checkCall(sig *signature, node *ast.CallExpression, args []Type, named []Type) Type {
+	count := len(args) + len(named)
+	if !ast.Accepts(sig.parameters, count) {
+		c.errorf("wrong number of arguments to %s: expected %s, got %d", sig.name, ast.Arity(sig.parameters), count)
+	}
+	for i, arg := range args {
+		param := parameterOf(sig, i)
+		if param == nil || param.Type == nil {
+			continue
+		}
+		if mismatch := c.checkAnnotation(param.Type, arg); mismatch != "" {
+			c.errorAt(node.Arguments[i].Pos(), "argument %s of %s: %s", param.Name.Value, sig.name, mismatch)
+		}
+	}
+	if len(node.NamedArguments) > 0 {
+		c.checkNamedArguments(sig, node, len(args), named)
+	}
+	return c.resolve(sig.returnType)
+}
+
+// checkNamedArguments checks the arguments passed by name in the call node against the
+// parameters of sig that the positional arguments left.
+func (c *Checker) checkNamedArguments(sig *signature, node *ast.CallExpression, positional int, named []Type) {
+	given := map[string]bool{}
+	for i, arg := range node.NamedArguments {
+		name := arg.Name.Value
+		index := -1
+		for j, param := range sig.parameters {
+			if param.Name.Value == name {
+				index = j
+			}
+		}
+
+		switch {
+		case index < 0:
+			c.errorAt(arg.Name.Pos(), "%s has no parameter %s", sig.name, name)
+		case sig.parameters[index].Variadic:
+			c.errorAt(arg.Name.Pos(), "variadic parameter %s of %s cannot be given by name", name, sig.name)
+		case index < positional || given[name]:
+			c.errorAt(arg.Name.Pos(), "argument %s of %s is given more than once", name, sig.name)
+		default:
+			param := sig.parameters[index]
+			if param.Type != nil {
+				if mismatch := c.checkAnnotation(param.Type, named[i]); mismatch != "" {
+					c.errorAt(arg.Value.Pos(), "argument %s of %s: %s", name, sig.name, mismatch)
+				}
+			}
+		}
+		given[name] = true
+	}
+
+	if !ast.Accepts(sig.parameters, positional+len(named)) {
+		return //already reported as the wrong number of arguments
+	}
+	for i, param := range sig.parameters {
+		if i >= positional && !given[param.Name.Value] && param.Default == nil && !param.Variadic {
+			c.errorf("missing argument %s of %s", param.Name.Value, sig.name)
+		}
+	}
+}
+
+// parameterOf returns the parameter the argument at index i is passed to, the
+// variadic parameter takes the extra arguments. It returns nil for too many arguments.
+func parameterOf(sig *signature, i int) *ast.FunctionParameter {
+	if i < len(sig.parameters) {
+		return sig.parameters[i]
+	}
+	if len(sig.parameters) > 0 && sig.parameters[len(sig.parameters)-1].Variadic {
+		return sig.parameters[len(sig.parameters)-1]
+	}
+	return nil
+}
+
+func (c *Checker) inferAssign(node *ast.AssignExpression, s *scope) Type {
+	value := c.infer(node.Value, s)
+
+	declared := Unknown
+	switch target := node.Target.(type) {
+	case *ast.Identifier:
+		//an assigned name is declared with an unknown type, it can hold any value
+		c.infer(target, s)
+	case *ast.MemberExpression:
+		declared = c.inferMember(target, s)
+	case *ast.IndexExpression:
+		c.infer(target, s)
+	}
+
+	if node.Operator != "=" {
+		value = c.inferInfix(strings.TrimSuffix(node.Operator, "="), declared, value)
+	}
+	if mismatch := c.compatible(declared, value); mismatch != "" {
+		c.errorf("cannot assign %s to %s of type %s", value, node.Target.String(), declared)
+	}
+	return value
+}
+
+func (c *Checker) inferStructLiteral(node *ast.StructLiteral, s *scope) Type {
+	values := []Type{}
+	for _, value := range node.Values {
+		values = append(values, c.infer(value, s))
+	}
+
+	info, ok := c.structs[node.Name.Value]
+	if node.Module != nil || !ok {
+		return Unknown
+	}
+
+	for i, name := range node.Fields {
+		var field *ast.StructField
+		for _, f := range info.node.Fields {
+			if f.Name.Value == name.Value {
+				field = f
+			}
+		}
+		if field == nil {
+			c.errorAt(name.Pos(), "unknown field %s in struct %s", name.Value, node.Name.Value)
+			continue
+		}
+		c.checkAccess(node.Name.Value, name.Value)
+		if mismatch := c.compatible(c.resolve(field.Type), values[i]); mismatch != "" {
+			c.errorAt(name.Pos(), "field %s of %s: %s", name.Value, node.Name.Value, mismatch)
+		}
+	}
+	return Type(node.Name.Value)
+}
+
+func (c *Checker) inferMember(node *ast.MemberExpression, s *scope) Type {
+	return c.inferMemberOf(c.infer(node.Object, s), node.Property.Value)
+}
+
+func (c *Checker) inferMemberOf(receiver Type, name string) Type {
+	if _, ok := c.structs[string(receiver)]; ok {
+		owner, field, method := c.findMember(string(receiver), name)
+		if owner == "" {
+			c.errorf("unknown field or method %s in struct %s", name, receiver)
+			return Unknown
+		}
+		c.checkAccess(owner, name)
+		if method != nil {
+			return Function
+		}
+		return c.resolve(field.Type)
+	}
+
+	for _, builtin := range annotations {
+		if receiver == builtin {
+			c.errorf("member access not supported: %s", receiver)
+		}
+	}
+	return Unknown
+}
@@ -0,0 +1,220 @@
+package typecheck
+
+import (
+	"Goslang/ast"
+	"Goslang/lexer"
+	"Goslang/parser"
+	"strings"
+	"testing"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser has %d errors for %q: %v", len(p.Errors()), input, p.Errors())
+	}
+	return program
+}
+
+func checkErrors(t *testing.T, input string, errors []string, expected []string) {
+	if len(errors) != len(expected) {
+		t.Errorf("wrong number of errors for %q. expected=%q, got=%q", input, expected, errors)
+		return
+	}
+	for i, msg := range expected {
+		if errors[i] != msg {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", input, msg, errors[i])
+		}
+	}
+}
+
+// withoutPositions removes the "line:column: " prefix of the errors.
+func withoutPositions(errors []string) []string {
+	messages := []string{}
+	for _, msg := range errors {
+		messages = append(messages, msg[strings.Index(msg, ": ")+2:])
+	}
+	return messages
+}
+
+func TestValidPrograms(t *testing.T) {
+	tests := []string{
+		`fn add(a:int, b:int):int { return a + b; } var x = add(1, 2); x += 1; x;`,
+		`fn half(x:float):float { return x / 2; } half(3); var f = 1.5; f = 2;`,
+		`var s = "a" + "b"; s < "c"; len(s) + 1;`,
+		`var total = 0; for (var i = 0; i < 10; i++) { total += i; } total;`,
+		`foreach (c in "abc") { c + "!"; }`,
+		`var x = 5; if (x > 1 && x < 10) { x = 7; } else { x--; }`,
+		`var double = fn(n:int):int { return n * 2; }; double(4);`,
+		`fn apply(f:fn, x:int) { return f(x); } apply(fn(n:int):int { return n; }, 1);`,
+		`fn counter() { var n = 0; return fn() { n += 1; return n; }; } var c = counter(); c();`,
+		`struct Point { x:int, y:int } fn Point.sum():int { return self.x + self.y; } Point{x: 1}.sum() + 1;`,
+		`struct Point { x:int } fn Point.move(dx:int) { self.x += dx; return self; } var p = Point{}; p.move(1).x;`,
+		`interface Shape { area():int } struct Sq { s:int } fn Sq.area():int { return self.s * self.s; }
+		 fn describe(s:Shape):int { return s.area(); } describe(Sq{s: 2});`,
+		`struct Animal { protected name:string } struct Dog { Animal } fn Dog.rename(n:string) { self.name = n; }`,
+		`var h = {"a": 1}; h["a"] = "x"; keys(h); unknownBuiltin(1) + "a";`,
+		`import "math/geometry" as geo; geo.area(1) + 1; var p = geo.Point{x: 1};`,
+		`fn pick(x:int) { if (x > 0) { return "pos"; } return x; }`,
+		`try { Atoi("a"); } catch (e) { e.message + "!"; throw e; } finally { var done = truth; }`,
+		`var t = ""; try { 1 + "a"; len(1, 2); } catch (e) { t = e.type; } t + "!";`,
+		`fn f(x:int, y:int = x * 2):int { return x + y; } f(1); f(1, 2);`,
+		`fn sum(...n:int):int { len(n); return 0; } sum(); sum(1, 2, 3);`,
+		`var s = "a"; match (s) { "a" | "b" => 1, x if len(x) > 2 => x + "!", [a, b] => a, _ => 0 };`,
+		`var total = 0; total += 1.5; var x = 2; x = x * 0.5; x = "five";`,
+		`fn half(n:int):float { return n / 2.0; } var r = 1; r = half(2); r + 0.5;`,
+		`foreach (c in "ab") { c = 1; c + 1; }`,
+		`var s = "a"; fn twice() { return s * 2; } s = 3; twice();`,
+		`fn draw(x:int, y:int, color:string = "black") { return color; } draw(x: 1, y: 2); draw(1, color: "red", y: 2);`,
+	}
+
+	for _, input := range tests {
+		errors := New().Check(parse(t, input))
+		checkErrors(t, input, errors, []string{})
+	}
+}
+
+func TestTypeErrors(t *testing.T) {
+	definitions := `
+struct Point { x:int, y:int, private secret:int }
+struct Circle { r:int }
+interface Shape { area():int, perimeter():int }
+fn Circle.area():int { return self.r; }
+fn add(a:int, b:int):int { return a + b; }
+fn describe(s:Shape):int { return s.area(); }
+fn getX(p:Point):int { return p.x; }
+`
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`add("a", 1);`, []string{"argument a of add: expected INTEGER, got STRING"}},
+		{`add(1, 2.5); add(lie, "b");`, []string{
+			"argument b of add: expected INTEGER, got FLOAT",
+			"argument a of add: expected INTEGER, got BOOLEAN",
+			"argument b of add: expected INTEGER, got STRING",
+		}},
+		{`add(1);`, []string{"wrong number of arguments to add: expected 2, got 1"}},
+		{`var s = add(1, 2) + "x";`, []string{"type mismatch: INTEGER + STRING"}},
+		{`"a" - "b";`, []string{"unknown operator: STRING - STRING"}},
+		{`1.5 & 2;`, []string{"unknown operator: FLOAT & INTEGER"}},
+		{`-"a"; ~1.5; !5;`, []string{"unknown operator: -STRING", "unknown operator: ~FLOAT"}},
+		{`lie + truth;`, []string{"unknown operator: BOOLEAN + BOOLEAN"}},
+		{`var s = "a"; s++;`, []string{"unknown operator: STRING++"}},
+		{`fn f():int { return "one"; }`, []string{"return value of f: expected INTEGER, got STRING"}},
+		{`fn f(x:int):string { var y = x * 2; return y; }`, []string{"return value of f: expected STRING, got INTEGER"}},
+		{`var f = fn(x:int):int { return x + "a"; };`, []string{"type mismatch: INTEGER + STRING"}},
+		{`fn(x:int) { return x; }("a");`, []string{"argument x of anonymous function: expected INTEGER, got STRING"}},
+		{`var f = fn(x:int) { return x; }; f(lie);`, []string{"argument x of anonymous function: expected INTEGER, got BOOLEAN"}},
+		{`var x = 5; x(1);`, []string{"not a function: INTEGER"}},
+		{`getX(Circle{r: 1});`, []string{"argument p of getX: expected Point, got Circle"}},
+		{`describe(Circle{r: 1});`, []string{"argument s of describe: Circle does not implement Shape (missing methods: perimeter():int)"}},
+		{`describe(5);`, []string{"argument s of describe: INTEGER does not implement Shape (missing methods: area():int, perimeter():int)"}},
+		{`Point{x: "a", z: 1};`, []string{"field x of Point: expected INTEGER, got STRING", "unknown field z in struct Point"}},
+		{`var p = Point{}; p.z; p.x + "a";`, []string{"unknown field or method z in struct Point", "type mismatch: INTEGER + STRING"}},
+		{`var p = Point{}; p.secret;`, []string{"cannot access private member secret of struct Point"}},
+		{`var p = Point{}; p.x = "a";`, []string{"cannot assign STRING to p.x of type INTEGER"}},
+		{`var c = Circle{}; c.area() + "a";`, []string{"type mismatch: INTEGER + STRING"}},
+		{`var x = 5; x.y;`, []string{"member access not supported: INTEGER"}},
+		{`fn f(p:Missing):Nothing { return p; }`, []string{"parameter p of f: unknown type Missing", "return type of f: unknown type Nothing"}},
+		{`struct Box { item:Missing }`, []string{"field item of Box: unknown type Missing"}},
+		{`len("a") + "b";`, []string{"type mismatch: INTEGER + STRING"}},
+		{`fn Point.scale(k:int):Point { return k; }`, []string{"return value of scale: expected Point, got INTEGER"}},
+		{`later(1); fn later(s:string) { return s; }`, []string{"argument s of later: expected STRING, got INTEGER"}},
+		{`throw 5;`, []string{"throw expects a STRING or an Error, got INTEGER"}},
+		{`fn f(x:int, y:int = 1) { return x; } f(); f(1, 2, 3);`, []string{
+			"wrong number of arguments to f: expected 1 to 2, got 0",
+			"wrong number of arguments to f: expected 1 to 2, got 3",
+		}},
+		{`fn f(x:int = "a") { return x; }`, []string{"default value of x in f: expected INTEGER, got STRING"}},
+		{`fn f(a:string, ...n:int) { return n + 1; } f(); f("a", 1, "b");`, []string{
+			"type mismatch: ARRAY + INTEGER",
+			"wrong number of arguments to f: expected at least 1, got 0",
+			"argument n of f: expected INTEGER, got STRING",
+		}},
+		{`add(1, b: "x"); add(b: 1, c: 2); add(1, a: 2);`, []string{
+			"argument b of add: expected INTEGER, got STRING",
+			"add has no parameter c",
+			"missing argument a of add",
+			"argument a of add is given more than once",
+			"missing argument b of add",
+		}},
+		{`match (1) { n if n + "a" => n - "b", _ => { var t = "x"; t * 2; } };`, []string{
+			"type mismatch: INTEGER + STRING",
+			"type mismatch: INTEGER - STRING",
+			"type mismatch: STRING * INTEGER",
+		}},
+		{`var c = "abc"[0] - 1; var n = ord("a") + "b"; chars("ab") + 1;`, []string{
+			"type mismatch: STRING - INTEGER",
+			"type mismatch: INTEGER + STRING",
+			"type mismatch: ARRAY + INTEGER",
+		}},
+		{`var s = "sum: ${add(1, "2")}"; s + 1;`, []string{
+			"argument b of add: expected INTEGER, got STRING",
+			"type mismatch: STRING + INTEGER",
+		}},
+		{`fn f(a:int, ...n:int) { return a; } f(a: 1, n: 2);`, []string{"variadic parameter n of f cannot be given by name"}},
+		{`try { add(1, "b"); } catch (e) { e + 1; "a" * 2; } finally { "a" - 1; }`, []string{
+			"type mismatch: STRING * INTEGER",
+			"type mismatch: STRING - INTEGER",
+		}},
+		{`try { add(1, "b"); } finally { 1; }`, []string{"argument b of add: expected INTEGER, got STRING"}},
+		{`try { fn later(x:int) { return x + "a"; } var f = fn() { return add("a", 1); }; 1 - "b"; } catch (e) { e; }`, []string{
+			"type mismatch: INTEGER + STRING",
+			"argument a of add: expected INTEGER, got STRING",
+		}},
+	}
+
+	for _, tt := range tests {
+		errors := New().Check(parse(t, definitions+tt.input))
+		checkErrors(t, tt.input, withoutPositions(errors), tt.expected)
+	}
+}
+
+func TestCheckerKeepsDeclarations(t *testing.T) {
+	checker := New()
+	checkErrors(t, "first line", checker.Check(parse(t, `fn add(a:int, b:int):int { return a + b; } var x = 1;`)), []string{})
+	checkErrors(t, "second line", withoutPositions(checker.Check(parse(t, `add(x, "b");`))), []string{"argument b of add: expected INTEGER, got STRING"})
+}
+
+func TestIncludedDeclarations(t *testing.T) {
+	included := parse(t, `
+private fn helper(s:string) { return s; }
+fn double(n:int):int { return n * 2; }
+struct Point { x:int }
+private struct Hidden { x:int }
+`)
+	program := parse(t, `#add "lib" double("a"); helper(1); fn f(p:Point, h:Hidden) { return p.x; }`)
+	program.Statements[0].(*ast.IncludeStatement).Program = included
+
+	errors := New().Check(program)
+	checkErrors(t, "include", withoutPositions(errors), []string{
+		"argument n of double: expected INTEGER, got STRING",
+		"parameter h of f: unknown type Hidden",
+	})
+}
+
+func TestErrorPositions(t *testing.T) {
+	input := `fn add(a:int, b:int):int {
+	return a + b;
+}
+add(1,
+    "two");
+fn f(p:Missing) {
+	var x = 5;
+	x - "five";
+}`
+	p := parser.New(lexer.NewFile("main.slang", input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors: %v", p.Errors())
+	}
+
+	errors := New().Check(program)
+	checkErrors(t, "positions", errors, []string{
+		"main.slang:5:5: argument b of add: expected INTEGER, got STRING",
+		"main.slang:6:6: parameter p of f: unknown type Missing",
+		"main.slang:8:2: type mismatch: INTEGER - STRING",
+	})
+}